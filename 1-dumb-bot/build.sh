@@ -2,7 +2,8 @@
 
 export PROJECT_ID=microbot-hackathon
 
-pack build --builder=gcr.io/buildpacks/builder gcr.io/$PROJECT_ID/dumb-bot
+# the cloudbowl module lives at the root of the repo, so the image is built from there
+docker build -f Dockerfile -t gcr.io/$PROJECT_ID/dumb-bot ..
 
 docker push gcr.io/$PROJECT_ID/dumb-bot
 
@@ -1,50 +1,25 @@
 package main
 
 import (
-	"encoding/json"
-	"fmt"
 	"log"
 	rand2 "math/rand"
 	"net/http"
-	"os"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
 )
 
 func main() {
-	port := "8080"
-	if v := os.Getenv("PORT"); v != "" {
-		port = v
-	}
-	http.HandleFunc("/", handler)
-
-	log.Printf("starting server on port :%s", port)
-	err := http.ListenAndServe(":"+port, nil)
+	http.HandleFunc("/", cloudbowl.BotHandler(play))
+	err := cloudbowl.ListenAndServe(nil)
 	log.Fatalf("http listen error: %v", err)
 }
 
-func handler(w http.ResponseWriter, req *http.Request) {
-	if req.Method == http.MethodGet {
-		fmt.Fprint(w, "Let the battle begin!")
-		return
-	}
-
-	var v ArenaUpdate
-	defer req.Body.Close()
-	d := json.NewDecoder(req.Body)
-	d.DisallowUnknownFields()
-	if err := d.Decode(&v); err != nil {
-		log.Printf("WARN: failed to decode ArenaUpdate in response body: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	resp := play(v)
-	fmt.Fprint(w, resp)
-}
-
-func play(input ArenaUpdate) (response string) {
+// picks a random move, leaving out the ones that would do nothing, e.g. walking into a wall
+func play(input cloudbowl.ArenaUpdate) (response cloudbowl.Move) {
 	log.Printf("IN: %#v", input)
 
-	commands := []string{"F", "R", "L", "T"}
-	rand := rand2.Intn(4)
+	myState := input.Arena.State[input.Links.Self.Href]
+	commands := cloudbowl.LegalMoves(input, myState)
+	rand := rand2.Intn(len(commands))
 	return commands[rand]
 }
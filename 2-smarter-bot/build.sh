@@ -2,7 +2,8 @@
 
 export PROJECT_ID=cloudbowl-356114
 
-pack build --builder=gcr.io/buildpacks/builder gcr.io/$PROJECT_ID/cloudbowl-samples-go-smart
+# the cloudbowl module lives at the root of the repo, so the image is built from there
+docker build -f Dockerfile -t gcr.io/$PROJECT_ID/cloudbowl-samples-go-smart ..
 
 docker push gcr.io/$PROJECT_ID/cloudbowl-samples-go-smart
 
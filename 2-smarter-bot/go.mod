@@ -1,4 +1,8 @@
-// +heroku goVersion 1.14
+// +heroku goVersion 1.17
 module github.com/GoogleCloudPlatform/cloudbowl-microservice-game/samples/go
 
-go 1.14
+go 1.17
+
+require github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl v0.0.0-00010101000000-000000000000
+
+replace github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl => ../cloudbowl
@@ -2,67 +2,43 @@ package main
 
 import (
 	"encoding/json"
-	"fmt"
 	"log"
 	"math"
-	// rand2 "math/rand"
 	"net/http"
-	"os"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
 )
 
 func main() {
-	port := "8080"
-	if v := os.Getenv("PORT"); v != "" {
-		port = v
-	}
-	http.HandleFunc("/", handler)
-
-	log.Printf("starting server on port :%s", port)
-	err := http.ListenAndServe(":"+port, nil)
+	http.HandleFunc("/", cloudbowl.BotHandler(func(input cloudbowl.ArenaUpdate) cloudbowl.Move {
+		jsonReq, _ := json.Marshal(input)
+		log.Printf("received ArenaUpdate: %s", jsonReq)
+		return play(input)
+	}))
+	err := cloudbowl.ListenAndServe(nil)
 	log.Fatalf("http listen error: %v", err)
 }
 
-func handler(w http.ResponseWriter, req *http.Request) {
-	if req.Method == http.MethodGet {
-		fmt.Fprint(w, "Let the battle begin!")
-		return
-	}
-
-	var v ArenaUpdate
-	defer req.Body.Close()
-	d := json.NewDecoder(req.Body)
-	d.DisallowUnknownFields()
-	if err := d.Decode(&v); err != nil {
-		log.Printf("WARN: failed to decode ArenaUpdate in response body: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	jsonReq, _ := json.Marshal(v)
-	log.Printf("received ArenaUpdate: %s", jsonReq)
-	resp := play(v)
-	fmt.Fprint(w, resp)
-}
-
-func play(input ArenaUpdate) (response string) {
+func play(input cloudbowl.ArenaUpdate) (response cloudbowl.Move) {
 	log.Printf("IN: %#v", input)
 	board := generateBoard(input)
 	myState := extractMyState(input)
-	if someoneIsInFrontOfMe(myState, board) {
+	if cloudbowl.IsAnyoneInFrontOf(input, myState) {
 		log.Println("throwing because someone is in front of me")
-		return "T"
+		return cloudbowl.Throw
 	} else {
-		return moveTowardsNextClosestPlayer(myState, board)
+		return moveTowardsNextClosestPlayer(input, myState, board)
 	}
 }
 
-func extractMyState(input ArenaUpdate) PlayerState {
+func extractMyState(input cloudbowl.ArenaUpdate) cloudbowl.PlayerState {
 	myId := input.Links.Self.Href
 	state := input.Arena.State
 	return state[myId]
 }
 
 // creates a 2D array of booleans representing locations of players on the board
-func generateBoard(input ArenaUpdate) [][]bool {
+func generateBoard(input cloudbowl.ArenaUpdate) [][]bool {
 	// generate the board data structure
 	width := input.Arena.Dimensions[0]
 	height := input.Arena.Dimensions[1]
@@ -81,71 +57,93 @@ func generateBoard(input ArenaUpdate) [][]bool {
 	return board
 }
 
-func moveTowardsNextClosestPlayer(myState PlayerState, board [][]bool) (response string) {
+func moveTowardsNextClosestPlayer(input cloudbowl.ArenaUpdate, myState cloudbowl.PlayerState, board [][]bool) (response cloudbowl.Move) {
 	opponentCoords := determineNextClosestPlayer(myState, board)
-	return determineNextMove(myState, opponentCoords)
+	return determineNextMove(input, myState, opponentCoords)
 }
 
-func determineNextMove(myState PlayerState, opponentCoords []int) string {
+func determineNextMove(input cloudbowl.ArenaUpdate, myState cloudbowl.PlayerState, opponentCoords []int) cloudbowl.Move {
 	directionImFacing := myState.Direction
 	directionOfOpponent := determineDirectionOfOpponent(myState, opponentCoords)
+	// walking into a wall or another player wastes the turn, so turn towards the opponent instead
+	if cloudbowl.IsForwardBlocked(input, myState) {
+		switch directionImFacing {
+		case cloudbowl.North:
+			if directionOfOpponent == "NW" || directionOfOpponent == "W" || directionOfOpponent == "SW" {
+				return cloudbowl.TurnLeft
+			}
+		case cloudbowl.East:
+			if directionOfOpponent == "NE" || directionOfOpponent == "N" || directionOfOpponent == "NW" {
+				return cloudbowl.TurnLeft
+			}
+		case cloudbowl.South:
+			if directionOfOpponent == "SE" || directionOfOpponent == "E" || directionOfOpponent == "NE" {
+				return cloudbowl.TurnLeft
+			}
+		default: // W
+			if directionOfOpponent == "SW" || directionOfOpponent == "S" || directionOfOpponent == "SE" {
+				return cloudbowl.TurnLeft
+			}
+		}
+		return cloudbowl.TurnRight
+	}
 	switch directionImFacing {
-	case "N":
+	case cloudbowl.North:
 		switch directionOfOpponent {
 		case "N":
 			fallthrough
 		case "NE":
 			fallthrough
 		case "NW":
-			return "F"
+			return cloudbowl.Forward
 		case "E":
 			fallthrough
 		case "SE":
 			fallthrough
 		case "S":
-			return "R"
+			return cloudbowl.TurnRight
 		case "SW":
 			fallthrough
 		default: // "W":
-			return "L"
+			return cloudbowl.TurnLeft
 		}
-	case "E":
+	case cloudbowl.East:
 		switch directionOfOpponent {
 		case "N":
 			fallthrough
 		case "NW":
-			return "L"
+			return cloudbowl.TurnLeft
 		case "NE":
 			fallthrough
 		case "E":
 			fallthrough
 		case "SE":
-			return "F"
+			return cloudbowl.Forward
 		case "S":
 			fallthrough
 		case "SW":
 			fallthrough
 		default: // "W":
-			return "R"
+			return cloudbowl.TurnRight
 		}
-	case "S":
+	case cloudbowl.South:
 		switch directionOfOpponent {
 		case "N":
 			fallthrough
 		case "W":
 			fallthrough
 		case "NW":
-			return "R"
+			return cloudbowl.TurnRight
 		case "NE":
 			fallthrough
 		case "E":
-			return "L"
+			return cloudbowl.TurnLeft
 		case "SE":
 			fallthrough
 		case "S":
 			fallthrough
 		default: // "SW":
-			return "F"
+			return cloudbowl.Forward
 		}
 	default: //W
 		switch directionOfOpponent {
@@ -154,72 +152,22 @@ func determineNextMove(myState PlayerState, opponentCoords []int) string {
 		case "NE":
 			fallthrough
 		case "E":
-			return "R"
+			return cloudbowl.TurnRight
 		case "SE":
 			fallthrough
 		case "S":
-			return "L"
+			return cloudbowl.TurnLeft
 		case "SW":
 			fallthrough
 		case "W":
 			fallthrough
 		default: // "NW":
-			return "F"
+			return cloudbowl.Forward
 		}
 	}
-
-	// switch directionOfOpponent {
-	// case "N":
-	// 	switch directionImFacing {
-	// 	case "N":
-	// 		return "F"
-	// 	case "E":
-	// 		return "R"
-	// 	case "S":
-	// 		return "R"
-	// 	default: //W
-	// 		return "L"
-	// 	}
-	// case "E":
-	// 	switch directionImFacing {
-	// 	case "N":
-	// 		return "R"
-	// 	case "E":
-	// 		return "F"
-	// 	case "S":
-	// 		return "R"
-	// 	default: //W
-	// 		return "L"
-	// 	}
-	// case "S":
-	// 	switch directionImFacing {
-	// 	case "N":
-	// 		return "R"
-	// 	case "E":
-	// 		return "R"
-	// 	case "S":
-	// 		return "F"
-	// 	default: //W
-	// 		return "L"
-	// 	}
-	// default: //W
-	// 	switch directionImFacing {
-	// 	case "N":
-	// 		return "L"
-	// 	case "E":
-	// 		return "R"
-	// 	case "S":
-	// 		return "R"
-	// 	default: //W
-	// 		return "F"
-	// 	}
-	// }
-	// commands := []string{"F", "R", "L"}
-	// rand := rand2.Intn(3)
-	// return commands[rand]
 }
 
-func determineDirectionOfOpponent(myState PlayerState, opponentCoords []int) string {
+func determineDirectionOfOpponent(myState cloudbowl.PlayerState, opponentCoords []int) string {
 	myXcoord := myState.X
 	myYcoord := myState.Y
 	result := ""
@@ -253,17 +201,17 @@ func determineDirectionOfOpponent(myState PlayerState, opponentCoords []int) str
 	return result
 }
 
-func determineNextClosestPlayer(myState PlayerState, board [][]bool) []int {
+func determineNextClosestPlayer(myState cloudbowl.PlayerState, board [][]bool) []int {
 	closestCoords := []int{0, 0}
-	closestDistance := -1.0
+	closestDistance := -1
 	for x := range board {
 		for y := range board[x] {
 			if x == myState.X && y == myState.Y { // skip ourselves
 				continue
 			}
 			if board[x][y] { // if there's a player at this location
-				currentDistance := calculateDistance(myState, x, y)
-				if closestDistance == -1 || currentDistance < closestDistance {
+				currentDistance := calculateDistance(myState, x, y, board)
+				if currentDistance != -1 && (closestDistance == -1 || currentDistance < closestDistance) {
 					closestDistance = currentDistance
 					closestCoords = []int{x, y}
 				}
@@ -273,46 +221,68 @@ func determineNextClosestPlayer(myState PlayerState, board [][]bool) []int {
 	return closestCoords
 }
 
-func calculateDistance(myState PlayerState, x2 int, y2 int) float64 {
+// counts the moves it would take before we could throw at the player at x2,y2: the steps forward to get in line with
+// them within throwing range, plus the turns needed along the way given the way we are facing. Other players are
+// ignored, and -1 means there is nowhere on the board to throw at them from.
+func calculateDistance(myState cloudbowl.PlayerState, x2 int, y2 int, board [][]bool) int {
 	x1 := myState.X
 	y1 := myState.Y
-	return math.Sqrt(math.Pow(float64(x2-x1), 2) + math.Pow(float64(y2-y1), 2))
-}
-
-// determines if there is a player in our firing line or not
-func someoneIsInFrontOfMe(myState PlayerState, board [][]bool) (result bool) {
-	myXcoord := myState.X
-	myYcoord := myState.Y
-	myDirection := myState.Direction
-	boardWidth := len(board)
-	boardHeight := len(board[0])
-	maxThrowLength := 3
-	switch myDirection {
-	case "N":
-		for i := 1; i <= maxThrowLength; i++ {
-			if myYcoord-i >= 0 && board[myXcoord][myYcoord-i] { // check we dont go outside north border
-				return true
-			}
+	result := -1
+	for _, facing := range cloudbowl.Directions {
+		// the square nearest to us from which facing this way would put them in range
+		dx, dy := facing.Step()
+		fromX := x2 - dx*clamp(dx*(x2-x1), 1, cloudbowl.MAX_THROW_DISTANCE)
+		fromY := y2 - dy*clamp(dy*(y2-y1), 1, cloudbowl.MAX_THROW_DISTANCE)
+		if fromX < 0 || fromX >= len(board) || fromY < 0 || fromY >= len(board[0]) {
+			continue
 		}
-	case "E":
-		for i := 1; i <= maxThrowLength; i++ {
-			if myXcoord+i < boardWidth && board[myXcoord+i][myYcoord] { // check we dont go outside the east border
-				return true
-			}
+		// walk across then up or down, or the other way round, whichever needs fewer turns
+		across := directionOfTravel(fromX-x1, cloudbowl.East, cloudbowl.West)
+		upOrDown := directionOfTravel(fromY-y1, cloudbowl.South, cloudbowl.North)
+		turns := countTurns(myState.Direction, across, upOrDown, facing)
+		if other := countTurns(myState.Direction, upOrDown, across, facing); other < turns {
+			turns = other
 		}
-	case "S":
-		for i := 1; i <= maxThrowLength; i++ {
-			if myYcoord+i < boardHeight && board[myXcoord][myYcoord+i] { // check we dont go outside the south border
-				return true
-			}
+		distance := abs(fromX-x1) + abs(fromY-y1) + turns
+		if result == -1 || distance < result {
+			result = distance
 		}
-	default: // "W"
-		for i := 1; i <= maxThrowLength; i++ {
-			if myXcoord-i >= 0 && board[myXcoord-i][myYcoord] { // check we dont go outside west border
-				return true
-			}
+	}
+	return result
+}
+
+// the direction to walk to cover the distance, or an empty direction if there is no need to walk at all
+func directionOfTravel(distance int, positive cloudbowl.Direction, negative cloudbowl.Direction) cloudbowl.Direction {
+	if distance > 0 {
+		return positive
+	} else if distance < 0 {
+		return negative
+	}
+	return ""
+}
+
+// counts the quarter turns needed to face each of the directions in order, skipping empty ones
+func countTurns(facing cloudbowl.Direction, directions ...cloudbowl.Direction) (result int) {
+	for _, direction := range directions {
+		if direction == "" {
+			continue
+		}
+		switch direction {
+		case facing:
+		case facing.RotateLeft(), facing.RotateRight():
+			result++
+		default: // behind us
+			result += 2
 		}
+		facing = direction
 	}
-	return false
+	return result
+}
+
+func clamp(value int, min int, max int) int {
+	return int(math.Min(math.Max(float64(value), float64(min)), float64(max)))
+}
 
+func abs(value int) int {
+	return int(math.Abs(float64(value)))
 }
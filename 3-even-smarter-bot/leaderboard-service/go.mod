@@ -3,3 +3,7 @@ module leaderboard-service
 go 1.17
 
 require github.com/gomodule/redigo v1.8.9
+
+require github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl v0.0.0-00010101000000-000000000000
+
+replace github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl => ../../cloudbowl
@@ -7,14 +7,18 @@ import (
 	"net/http"
 	"os"
 	"sort"
+	"time"
 
-	"leaderboard-service/shared"
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl/actions"
 
 	"github.com/gomodule/redigo/redis"
 )
 
 var redisPool *redis.Pool
 
+var HIT_EXPIRY_SECONDS = 900 // forget who has been hitting a player once they haven't been hit for a while
+
 func init() {
 
 }
@@ -31,36 +35,34 @@ func main() {
 	}
 
 	http.HandleFunc("/", EventProcessor)
-	// Determine port for HTTP service.
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
 	// Start HTTP server.
-	log.Printf("Listening on port %s", port)
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
+	if err := cloudbowl.ListenAndServe(nil); err != nil {
 		log.Fatal(err)
 	}
 }
 
 func EventProcessor(w http.ResponseWriter, req *http.Request) {
-	var arenaUpdate shared.ArenaUpdate
-	var pubsubMessageEvent shared.PubSubMessageEvent
 	defer req.Body.Close()
-	d := json.NewDecoder(req.Body)
-	// d.DisallowUnknownFields()
-	if err := d.Decode(&pubsubMessageEvent); err != nil {
-		log.Printf("WARN: failed to decode PubSubMessageEvent in response body: %v", err)
+	arenaUpdate, received, err := cloudbowl.DecodeReceivedArenaUpdateEvent(req.Body)
+	if err != nil {
+		log.Printf("WARN: %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	err := json.Unmarshal(pubsubMessageEvent.Message.Data, &arenaUpdate)
+	conn := redisPool.Get()
+	defer conn.Close()
+	previous, fresh, err := swapArenaUpdate(conn, arenaUpdate, received)
 	if err != nil {
-		log.Fatalf("fatal error parsing ArenaUpdate: %v", err)
+		log.Printf("WARN: error swapping arena update in redis: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if !fresh {
+		log.Printf("skipping arena update received at %v, a later one has already been processed", received)
+		return
 	}
-	var leaderboard []shared.PlayerState
-	for k, v := range arenaUpdate.Arena.State {
-		v.Id = k
+	var leaderboard []cloudbowl.PlayerState
+	for _, v := range arenaUpdate.Arena.State { // each player's Id is filled in when the update is decoded
 		leaderboard = append(leaderboard, v)
 	}
 	// now sort the leaderboard
@@ -69,12 +71,116 @@ func EventProcessor(w http.ResponseWriter, req *http.Request) {
 		return leaderboard[i].Score > leaderboard[j].Score
 	})
 	log.Printf("sorted leaderboard is: %v", leaderboard)
-	conn := redisPool.Get()
-	defer conn.Close()
 	leaderboardAsByteArray, err := json.Marshal(leaderboard)
 	if err != nil {
 		log.Fatalf("fatal error marshalling leaderboard: %v", err)
 	}
 	log.Printf("updating leaderboard in redis to: %v", string(leaderboardAsByteArray))
 	conn.Do("SET", "leaderboard", string(leaderboardAsByteArray))
+	tick, err := redis.Int(conn.Do("INCR", "arena-tick")) // counts the updates, so players can tell how long ago hits were
+	if err != nil {
+		log.Printf("WARN: error counting arena update in redis: %v", err)
+		return
+	}
+	if previous == nil {
+		log.Printf("no previous arena update to compare with")
+		return
+	}
+	publishActions(conn, *previous, arenaUpdate, tick)
+}
+
+// swaps the stored arena update for the new one in a single step, so two updates processed at the same time don't both
+// see the same previous one. The update is only stored if it was received later than the stored one, since Pub/Sub can
+// deliver updates out of order and more than once.
+var swapArenaUpdateScript = redis.NewScript(2, `
+local received = redis.call('GET', KEYS[2])
+if received and tonumber(received) >= tonumber(ARGV[2]) then
+	return 0
+end
+local previous = redis.call('GET', KEYS[1])
+redis.call('SET', KEYS[1], ARGV[1])
+redis.call('SET', KEYS[2], ARGV[2])
+return previous
+`)
+
+// stores the arena update if it is newer than the stored one, returning the update it replaced, or nil if there wasn't
+// one. fresh is false if the update is no newer than the stored one, which is left alone.
+func swapArenaUpdate(conn redis.Conn, arenaUpdate cloudbowl.ArenaUpdate, received time.Time) (previous *cloudbowl.ArenaUpdate, fresh bool, err error) {
+	arenaUpdateAsByteArray, err := json.Marshal(arenaUpdate)
+	if err != nil {
+		log.Fatalf("fatal error marshalling arena update: %v", err)
+	}
+	reply, err := swapArenaUpdateScript.Do(conn, "arena-update", "arena-update-received", arenaUpdateAsByteArray, received.UnixMilli())
+	if err != nil {
+		return nil, false, err
+	}
+	switch reply := reply.(type) {
+	case nil:
+		return nil, true, nil
+	case int64:
+		return nil, false, nil
+	case []byte:
+		previous = &cloudbowl.ArenaUpdate{}
+		if err := json.Unmarshal(reply, previous); err != nil {
+			log.Printf("WARN: error unmarshalling previous arena update: %v", err)
+			return nil, true, nil
+		}
+		previous.AssignPlayerIds()
+		return previous, true, nil
+	default:
+		return nil, false, fmt.Errorf("unexpected reply %v", reply)
+	}
+}
+
+// works out what every player probably did since the previous update and stores it in redis, indexed by player
+func publishActions(conn redis.Conn, previous cloudbowl.ArenaUpdate, arenaUpdate cloudbowl.ArenaUpdate, tick int) {
+	inferences := actions.Infer(previous, arenaUpdate)
+	inferencesAsByteArray, err := json.Marshal(inferences)
+	if err != nil {
+		log.Fatalf("fatal error marshalling actions: %v", err)
+	}
+	log.Printf("updating actions in redis to: %v", string(inferencesAsByteArray))
+	conn.Do("SET", "actions", string(inferencesAsByteArray))
+	recordHits(conn, previous, arenaUpdate, tick)
+}
+
+// adds who hit whom since the previous update to the hit graph in redis, which holds a hash for each victim of how many
+// times each thrower has hit them. Hits that can't be pinned on one thrower count partly towards each suspect. Another
+// hash for each victim holds the arena-tick each thrower last hit them in. Both are forgotten once the victim hasn't
+// been hit for HIT_EXPIRY_SECONDS, so old grudges don't carry over from one match to the next.
+func recordHits(conn redis.Conn, previous cloudbowl.ArenaUpdate, arenaUpdate cloudbowl.ArenaUpdate, tick int) {
+	for _, hit := range actions.AttributeHits(previous, arenaUpdate) {
+		log.Printf("%v hit %v (%.0f%% sure)", hit.Thrower, hit.Victim, 100*hit.Confidence)
+		if err := recordHit(conn, hit, tick); err != nil {
+			log.Printf("WARN: error recording hit in redis: %v", err)
+		}
+	}
+}
+
+func recordHit(conn redis.Conn, hit actions.Hit, tick int) error {
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+	if err := conn.Send("HINCRBYFLOAT", hitsKey(hit.Victim), hit.Thrower, hit.Confidence); err != nil {
+		return err
+	}
+	if err := conn.Send("HSET", lastHitKey(hit.Victim), hit.Thrower, tick); err != nil {
+		return err
+	}
+	if err := conn.Send("EXPIRE", hitsKey(hit.Victim), HIT_EXPIRY_SECONDS); err != nil {
+		return err
+	}
+	if err := conn.Send("EXPIRE", lastHitKey(hit.Victim), HIT_EXPIRY_SECONDS); err != nil {
+		return err
+	}
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+func hitsKey(victim string) string {
+	return "hits:" + victim
+}
+
+func lastHitKey(victim string) string {
+	return "last-hit:" + victim
 }
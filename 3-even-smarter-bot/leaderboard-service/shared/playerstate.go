@@ -1,10 +0,0 @@
-package shared
-
-type PlayerState struct {
-	Id        string
-	X         int    `json:"x"`
-	Y         int    `json:"y"`
-	Direction string `json:"direction"`
-	WasHit    bool   `json:"wasHit"`
-	Score     int    `json:"score"`
-}
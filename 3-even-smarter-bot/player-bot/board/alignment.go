@@ -0,0 +1,30 @@
+package board
+
+import (
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// finds the shortest sequence of moves that leaves the player sharing a row or column with the target, between 1 and
+// maxDistance squares away and facing them with nobody in between, in a square the target can't hit without turning
+// first. The path there stays out of the target's line of fire too, unless there is no other way to get there. Like
+// PlanPathToFiringPosition, other players are treated as not moving. found is false if there is no such square to be
+// had, e.g. because the target's line of fire covers every one of them.
+func (board Board) PlanAlignment(myState cloudbowl.PlayerState, target cloudbowl.PlayerState, maxDistance int) (moves []cloudbowl.Move, found bool) {
+	exposed := make(map[Square]bool)
+	if target.Direction.Valid() {
+		for _, square := range board.lineOfFire(myState, target, pose{X: target.X, Y: target.Y, Direction: target.Direction}, maxDistance) {
+			exposed[square] = true
+		}
+	}
+	isGoal := func(current pose) bool {
+		if exposed[Square{X: current.X, Y: current.Y}] {
+			return false
+		}
+		opponent, found := board.firstInLineFrom(myState, current, maxDistance)
+		return found && opponent.Id == target.Id
+	}
+	if moves, found := board.planPath(myState, isGoal, func(square Square) bool { return !exposed[square] }); found {
+		return moves, true
+	}
+	return board.planPath(myState, isGoal, nil)
+}
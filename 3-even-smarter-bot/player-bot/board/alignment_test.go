@@ -0,0 +1,129 @@
+package board
+
+import (
+	"testing"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+func TestPlanAlignment(t *testing.T) {
+	tests := []struct {
+		name         string
+		width        int
+		height       int
+		me           cloudbowl.PlayerState
+		target       cloudbowl.PlayerState
+		others       []cloudbowl.PlayerState
+		want         []cloudbowl.Move // checked if not nil
+		notFound     bool
+		mayBeExposed bool // whether the path has to go through the target's line of fire
+	}{
+		{
+			name:   "already lined up",
+			me:     cloudbowl.PlayerState{X: 0, Y: 2, Direction: cloudbowl.East},
+			target: cloudbowl.PlayerState{X: 2, Y: 2, Direction: cloudbowl.North},
+			want:   []cloudbowl.Move{},
+		},
+		{
+			name:   "one turn away",
+			me:     cloudbowl.PlayerState{X: 0, Y: 2, Direction: cloudbowl.North},
+			target: cloudbowl.PlayerState{X: 2, Y: 2, Direction: cloudbowl.North},
+			want:   []cloudbowl.Move{cloudbowl.TurnRight},
+		},
+		{
+			name:   "lined up, but in their line of fire",
+			me:     cloudbowl.PlayerState{X: 0, Y: 2, Direction: cloudbowl.East},
+			target: cloudbowl.PlayerState{X: 3, Y: 2, Direction: cloudbowl.West},
+		},
+		{
+			name:   "going round their line of fire",
+			me:     cloudbowl.PlayerState{X: 3, Y: 4, Direction: cloudbowl.North},
+			target: cloudbowl.PlayerState{X: 6, Y: 3, Direction: cloudbowl.West},
+			others: []cloudbowl.PlayerState{{X: 6, Y: 4, Direction: cloudbowl.West}},
+		},
+		{
+			name:         "through their line of fire when there is no way round",
+			width:        4,
+			me:           cloudbowl.PlayerState{X: 1, Y: 4, Direction: cloudbowl.North},
+			target:       cloudbowl.PlayerState{X: 3, Y: 2, Direction: cloudbowl.West},
+			others:       []cloudbowl.PlayerState{{X: 3, Y: 3, Direction: cloudbowl.West}},
+			mayBeExposed: true,
+		},
+		{
+			name:     "nowhere out of their line of fire",
+			me:       cloudbowl.PlayerState{X: 4, Y: 4, Direction: cloudbowl.North},
+			target:   cloudbowl.PlayerState{X: 0, Y: 0, Direction: cloudbowl.East},
+			others:   []cloudbowl.PlayerState{{X: 0, Y: 1, Direction: cloudbowl.East}},
+			notFound: true,
+		},
+		{
+			name:   "someone in the way",
+			me:     cloudbowl.PlayerState{X: 0, Y: 2, Direction: cloudbowl.East},
+			target: cloudbowl.PlayerState{X: 3, Y: 2, Direction: cloudbowl.North},
+			others: []cloudbowl.PlayerState{{X: 1, Y: 2, Direction: cloudbowl.North}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			width, height := test.width, test.height
+			if width == 0 {
+				width = 7
+			}
+			if height == 0 {
+				height = 5
+			}
+			me, target := test.me, test.target
+			me.Id, target.Id = "me", "target"
+			players := map[string]cloudbowl.PlayerState{"me": me, "target": target}
+			for i, other := range test.others {
+				players[string(rune('a'+i))] = other
+			}
+			board := New(width, height, players)
+
+			moves, found := board.PlanAlignment(me, target, 3)
+			if found == test.notFound {
+				t.Fatalf("PlanAlignment() = %v, %v, want found = %v", moves, found, !test.notFound)
+			}
+			if !found {
+				return
+			}
+			if test.want != nil && (len(moves) != len(test.want) || (len(moves) > 0 && !equalMoves(moves, test.want))) {
+				t.Errorf("PlanAlignment() = %v, want %v", moves, test.want)
+			}
+
+			exposed := make(map[Square]bool)
+			for _, square := range board.lineOfFire(me, target, pose{X: target.X, Y: target.Y, Direction: target.Direction}, 3) {
+				exposed[square] = true
+			}
+			current := pose{X: me.X, Y: me.Y, Direction: me.Direction}
+			crossed := false
+			for _, move := range moves {
+				next, moved := board.nextPose(me, current, move)
+				if !moved {
+					t.Fatalf("PlanAlignment() = %v, but %v can't be made from %v", moves, move, current)
+				}
+				current = next
+				// we may have to start in their line of fire, so only the squares we step into count
+				crossed = crossed || (move == cloudbowl.Forward && exposed[Square{X: current.X, Y: current.Y}])
+			}
+			if crossed && !test.mayBeExposed {
+				t.Errorf("PlanAlignment() = %v goes through the target's line of fire", moves)
+			}
+			if exposed[Square{X: current.X, Y: current.Y}] {
+				t.Errorf("PlanAlignment() = %v ends in the target's line of fire at %v", moves, current)
+			}
+			if opponent, inLine := board.firstInLineFrom(me, current, 3); !inLine || opponent.Id != "target" {
+				t.Errorf("PlanAlignment() = %v ends at %v, which isn't lined up on the target", moves, current)
+			}
+		})
+	}
+}
+
+func equalMoves(a []cloudbowl.Move, b []cloudbowl.Move) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -3,146 +3,117 @@ package board
 import (
 	"log"
 	"math"
-	"player-bot/shared"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
 )
 
 type Board struct {
-	Squares         [][]*shared.PlayerState
+	Squares         [][]*cloudbowl.PlayerState
+	Players         map[string]*cloudbowl.PlayerState // indexed by each player's Id, the href they are known by in the arena
 	Width           int
 	Height          int
 	NumberOfPlayers int
-	// Leaderboard     []*shared.PlayerState
+	Index           Index // the players bucketed by row and column, for spatial queries
 }
 
-func New(width int, height int, players map[string]shared.PlayerState) Board {
+func New(width int, height int, players map[string]cloudbowl.PlayerState) Board {
 	board := Board{}
 	board.Width = width
 	board.Height = height
 	board.NumberOfPlayers = len(players)
-	// board.Leaderboard = make([]*shared.PlayerState, board.NumberOfPlayers)
-	board.Squares = make([][]*shared.PlayerState, width)
+	board.Players = make(map[string]*cloudbowl.PlayerState, len(players))
+	board.Squares = make([][]*cloudbowl.PlayerState, width)
 	for i := range board.Squares {
-		board.Squares[i] = make([]*shared.PlayerState, height)
+		board.Squares[i] = make([]*cloudbowl.PlayerState, height)
 	}
 
-	// now populate squares and leaderboard with players
-	var playerIndex = 0
-	for _, v := range players {
-		vX := v.X
-		vY := v.Y
-		board.Squares[vX][vY] = &v
-		// board.Leaderboard[playerIndex] = &v
-		playerIndex++
+	// now populate squares and players
+	for id, v := range players {
+		player := v // take a copy, otherwise every square would point at the same loop variable
+		player.Id = id
+		board.Squares[player.X][player.Y] = &player
+		board.Players[id] = &player
 	}
-	// now sort the leaderboard
-	// sort.Slice(board.Leaderboard, func(i, j int) bool {
-	// 	return board.Leaderboard[i].Score < board.Leaderboard[j].Score
-	// })
+	board.Index = NewIndex(width, height, board.Players)
 	log.Printf("board is: %v", board)
 	return board
 }
 
+// looks up a player by their Id
+func (board Board) Player(id string) (player cloudbowl.PlayerState, found bool) {
+	if p, exists := board.Players[id]; exists {
+		return *p, true
+	}
+	return player, false
+}
+
+// a location on the board
+type Square struct {
+	X int
+	Y int
+}
+
+func (board Board) IsOnBoard(x int, y int) bool {
+	return x >= 0 && x < board.Width && y >= 0 && y < board.Height
+}
+
 func (board Board) IsSquareOccupied(x int, y int) bool {
 	return board.Squares[x][y] != nil
 }
 
-func (board Board) IsSquareOccupiedByTargetOpponents(x int, y int, targetOpponents []shared.PlayerState) bool {
-	if board.IsSquareOccupied(x, y) {
-		for _, targetOpponent := range targetOpponents {
-			if *board.Squares[x][y] == targetOpponent {
-				return true
-			}
-		}
+// finds who is standing on the square, if anyone is, so the board can be used with the rules in cloudbowl
+func (board Board) PlayerAt(x int, y int) (id string, found bool) {
+	if !board.IsOnBoard(x, y) || board.Squares[x][y] == nil {
+		return "", false
 	}
-	return false
+	return board.Squares[x][y].Id, true
 }
 
 // determines if there is an opponent in front of provided player, within the max distance
-func (board Board) IsThereAnOpponentInFrontOfMe(myState shared.PlayerState, maxDistance int) (result bool) {
-	myXcoord := myState.X
-	myYcoord := myState.Y
-	myDirection := myState.Direction
-	switch myDirection {
-	case "N":
-		for i := 1; i <= maxDistance; i++ {
-			if myYcoord-i >= 0 && board.IsSquareOccupied(myXcoord, myYcoord-i) { // check we dont go outside north border
-				return true
-			}
-		}
-	case "E":
-		for i := 1; i <= maxDistance; i++ {
-			if myXcoord+i < board.Width && board.IsSquareOccupied(myXcoord+i, myYcoord) { // check we dont go outside the east border
-				return true
-			}
-		}
-	case "S":
-		for i := 1; i <= maxDistance; i++ {
-			if myYcoord+i < board.Height && board.IsSquareOccupied(myXcoord, myYcoord+i) { // check we dont go outside the south border
-				return true
-			}
-		}
-	default: // "W"
-		for i := 1; i <= maxDistance; i++ {
-			if myXcoord-i >= 0 && board.IsSquareOccupied(myXcoord-i, myYcoord) { // check we dont go outside west border
-				return true
-			}
-		}
+func (board Board) IsThereAnOpponentInFrontOfMe(myState cloudbowl.PlayerState, maxDistance int) (result bool) {
+	_, result = board.FirstOpponentInFrontOfMe(myState, maxDistance)
+	return result
+}
+
+// finds the player a throw from the provided player would hit, which is the nearest one in front of them within the max distance
+func (board Board) FirstOpponentInFrontOfMe(myState cloudbowl.PlayerState, maxDistance int) (opponent cloudbowl.PlayerState, found bool) {
+	if id, found := cloudbowl.FirstPlayerInLine(board, myState.X, myState.Y, myState.Direction, maxDistance); found {
+		return *board.Players[id], true
 	}
-	return false
+	return opponent, false
 }
 
-// determines if there is a high scoring opponent in front of provided player, within the max distance
-func (board Board) IsThereAHighScoringOpponentInFrontOfMe(myState shared.PlayerState, maxDistance int, leaderboard []shared.PlayerState, percentile float64) (result bool) {
-	myXcoord := myState.X
-	myYcoord := myState.Y
-	myDirection := myState.Direction
-	highScoringOpponents := getHighScoringOpponents(myState, leaderboard, percentile)
-	switch myDirection {
-	case "N":
-		for i := 1; i <= maxDistance; i++ {
-			if myYcoord-i >= 0 && board.IsSquareOccupiedByTargetOpponents(myXcoord, myYcoord-i, highScoringOpponents) { // check we dont go outside north border
-				return true
-			}
-		}
-	case "E":
-		for i := 1; i <= maxDistance; i++ {
-			if myXcoord+i < board.Width && board.IsSquareOccupiedByTargetOpponents(myXcoord+i, myYcoord, highScoringOpponents) { // check we dont go outside the east border
-				return true
-			}
-		}
-	case "S":
-		for i := 1; i <= maxDistance; i++ {
-			if myYcoord+i < board.Height && board.IsSquareOccupiedByTargetOpponents(myXcoord, myYcoord+i, highScoringOpponents) { // check we dont go outside the south border
-				return true
-			}
-		}
-	default: // "W"
-		for i := 1; i <= maxDistance; i++ {
-			if myXcoord-i >= 0 && board.IsSquareOccupiedByTargetOpponents(myXcoord-i, myYcoord, highScoringOpponents) { // check we dont go outside west border
-				return true
-			}
+// lists the squares in the direction the player is facing, nearest first, stopping at the max distance or the edge of the board
+func (board Board) squaresInFront(myState cloudbowl.PlayerState, maxDistance int) (result []Square) {
+	dx, dy := myState.Direction.Step()
+	if dx == 0 && dy == 0 { // not facing a valid direction
+		return nil
+	}
+	for i := 1; i <= maxDistance; i++ {
+		x := myState.X + dx*i
+		y := myState.Y + dy*i
+		if !board.IsOnBoard(x, y) {
+			break
 		}
+		result = append(result, Square{X: x, Y: y})
 	}
-	return false
+	return result
 }
 
-// TODO: optimise to search concentrically out from the player's location instead of scanning whole board
-func (board Board) FindClosestOpponent(myState shared.PlayerState) shared.PlayerState {
-	closestOpponent := shared.PlayerState{}
-	closestDistance := -1.0
-	for x := range board.Squares {
-		for y := range board.Squares[x] {
-			if x == myState.X && y == myState.Y { // skip ourselves
-				continue
-			}
-			if board.IsSquareOccupied(x, y) {
-				currentDistance := calculateDistance(myState.X, myState.Y, x, y)
-				if closestDistance == -1 || currentDistance < closestDistance {
-					closestDistance = currentDistance
-					closestOpponent = *board.Squares[x][y]
-				}
-			}
-		}
+// finds the opponent we could throw at soonest, counting the turns as well as the steps forward it would take to line
+// up on them. If nobody can be lined up on, falls back to the nearest opponent as the crow flies.
+func (board Board) FindClosestOpponent(myState cloudbowl.PlayerState, maxDistance int) cloudbowl.PlayerState {
+	closestOpponent := cloudbowl.PlayerState{}
+	notMe := func(player cloudbowl.PlayerState) bool { return player.Id != myState.Id }
+	// the opponent we can line up on soonest is nearly always one of the nearest as the crow flies
+	candidates := board.Index.NearestK(myState.X, myState.Y, ACTION_DISTANCE_CANDIDATES, notMe)
+	for id, ticks := range board.actionDistances(myState, maxDistance, candidates, 1) {
+		closestOpponent = *board.Players[id]
+		log.Printf("returning closest opponent: %v, who I could throw at in %v ticks", closestOpponent, ticks)
+		return closestOpponent
+	}
+	if nearest := board.Index.NearestK(myState.X, myState.Y, 1, notMe); len(nearest) > 0 {
+		closestOpponent = nearest[0]
 	}
 	log.Printf("returning closest opponent: %v", closestOpponent)
 	return closestOpponent
@@ -151,13 +122,24 @@ func (board Board) FindClosestOpponent(myState shared.PlayerState) shared.Player
 /**
  * The percentile controls what makes a player a "high scorer" - a value of 0.1 means only the top 10% of scoring players count,
  * a value of 0.5 means the top 50% of players count etc.
+ * Like FindClosestOpponent, "closest" means the fewest ticks until we could throw at them.
  */
-func (board Board) FindClosestHighScoringOpponent(myState shared.PlayerState, leaderboard []shared.PlayerState, percentile float64) shared.PlayerState {
-	closestHighScoringOpponent := shared.PlayerState{}
-	closestDistance := math.MaxFloat64 // technically this means this method could fail with an incredibly huge board
+func (board Board) FindClosestHighScoringOpponent(myState cloudbowl.PlayerState, leaderboard []cloudbowl.PlayerState, percentile float64, maxDistance int) cloudbowl.PlayerState {
+	closestHighScoringOpponent := cloudbowl.PlayerState{}
 	highScoringOpponents := getHighScoringOpponents(myState, leaderboard, percentile)
+	for id, ticks := range board.actionDistances(myState, maxDistance, highScoringOpponents, 1) {
+		closestHighScoringOpponent = *board.Players[id]
+		log.Printf("returning closest highest scoring opponent: %v, who I could throw at in %v ticks", closestHighScoringOpponent, ticks)
+		return closestHighScoringOpponent
+	}
+	// none of them can be lined up on right now, so head for the nearest as the crow flies
+	closestDistance := math.MaxFloat64 // technically this means this method could fail with an incredibly huge board
 	for i := 0; i < len(highScoringOpponents); i++ {
-		opponent := highScoringOpponents[i]
+		// the leaderboard can be a tick behind, so use where the opponent is now
+		opponent, onBoard := board.Player(highScoringOpponents[i].Id)
+		if !onBoard {
+			continue
+		}
 		currentDistance := calculateDistance(myState.X, myState.Y, opponent.X, opponent.Y)
 		if currentDistance < closestDistance {
 			closestDistance = currentDistance
@@ -172,12 +154,17 @@ func calculateDistance(x1 int, y1 int, x2 int, y2 int) float64 {
 	return math.Sqrt(math.Pow(float64(x2-x1), 2) + math.Pow(float64(y2-y1), 2))
 }
 
+// lists the opponents in the top percentile of the leaderboard, leaving us out even if we are one of them
+func (board Board) HighScoringOpponents(myState cloudbowl.PlayerState, leaderboard []cloudbowl.PlayerState, percentile float64) []cloudbowl.PlayerState {
+	return getHighScoringOpponents(myState, leaderboard, percentile)
+}
+
 // Need to test for all the edge/corner cases or no leaderboard, current player being only player on the leaderboard, current player being a leader, current player not being a leader
-func getHighScoringOpponents(myState shared.PlayerState, leaderboard []shared.PlayerState, percentile float64) (result []shared.PlayerState) {
+func getHighScoringOpponents(myState cloudbowl.PlayerState, leaderboard []cloudbowl.PlayerState, percentile float64) (result []cloudbowl.PlayerState) {
 	log.Printf("determinig high scoring opponents: my score is: %v, leaderboard length is %v, percentile is: %v", myState.Score, len(leaderboard), percentile)
 	var maxIndex int = int(math.Round(float64(len(leaderboard)) * percentile))
 	for i := 0; i < maxIndex; i++ {
-		if leaderboard[i] != myState { // skip ourselves in case we are a high scorer
+		if leaderboard[i].Id != myState.Id { // skip ourselves in case we are a high scorer
 			result = append(result, leaderboard[i])
 		}
 
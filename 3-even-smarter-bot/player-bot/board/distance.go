@@ -0,0 +1,104 @@
+package board
+
+import (
+	"math"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+var ACTION_DISTANCE_CANDIDATES = 8 // how many of the nearest opponents ActionDistances looks for, to keep the search short on busy boards
+var ACTION_DISTANCE_SLACK = 4      // how many ticks past walking straight to the furthest candidate to keep searching, for turns and detours
+
+// counts the ticks it would take the player to be able to throw at each of the ACTION_DISTANCE_CANDIDATES opponents
+// nearest to them as the crow flies, indexed by the opponent's Id. That is the steps forward plus the turns needed given
+// the way the player is facing, walking around anyone in the way. A throw hits the first player in line, so an opponent
+// only counts once nobody else is in between. Opponents further away, or who can't be lined up on, are left out.
+func (board Board) ActionDistances(myState cloudbowl.PlayerState, maxDistance int) map[string]int {
+	notMe := func(player cloudbowl.PlayerState) bool { return player.Id != myState.Id }
+	return board.actionDistances(myState, maxDistance, board.Index.NearestK(myState.X, myState.Y, ACTION_DISTANCE_CANDIDATES, notMe), 0)
+}
+
+// the same as ActionDistances, but for a single opponent. reachable is false if they can't be lined up on at all.
+func (board Board) ActionDistance(myState cloudbowl.PlayerState, opponent cloudbowl.PlayerState, maxDistance int) (ticks int, reachable bool) {
+	distances := board.actionDistances(myState, maxDistance, []cloudbowl.PlayerState{opponent}, 1)
+	ticks, reachable = distances[opponent.Id]
+	return ticks, reachable
+}
+
+// the ticks it would take the player to be able to throw at the opponent, looked up in distances as found by
+// ActionDistances. There is no way to line up on an opponent it left out right now, so go by how far away they are
+// instead, plus a full turn since there is no telling which way the player will need to face.
+func estimateActionDistance(myState cloudbowl.PlayerState, opponent cloudbowl.PlayerState, distances map[string]int) int {
+	if ticks, reachable := distances[opponent.Id]; reachable {
+		return ticks
+	}
+	return int(math.Ceil(calculateDistance(myState.X, myState.Y, opponent.X, opponent.Y))) + len(cloudbowl.Directions)
+}
+
+// searches the poses the player could reach breadth first, recording the tick at which each candidate first becomes the
+// player a throw would hit. The search stops once limit candidates have been found, or all of them if limit is 0, and
+// gives up on the rest once it has gone ACTION_DISTANCE_SLACK ticks further than walking straight to the furthest
+// candidate would take. Other players are treated as not moving.
+func (board Board) actionDistances(myState cloudbowl.PlayerState, maxDistance int, candidates []cloudbowl.PlayerState, limit int) map[string]int {
+	result := make(map[string]int)
+	start := pose{X: myState.X, Y: myState.Y, Direction: myState.Direction}
+	if !start.Direction.Valid() || !board.IsOnBoard(start.X, start.Y) {
+		return result
+	}
+	isCandidate := make(map[string]bool, len(candidates))
+	maxTicks := 0
+	for _, candidate := range candidates {
+		// the leaderboard can be a tick behind, so use where the candidate is now
+		opponent, onBoard := board.Player(candidate.Id)
+		if !onBoard || opponent.Id == myState.Id {
+			continue
+		}
+		isCandidate[opponent.Id] = true
+		if ticks := abs(opponent.X-myState.X) + abs(opponent.Y-myState.Y) + ACTION_DISTANCE_SLACK; ticks > maxTicks {
+			maxTicks = ticks
+		}
+	}
+	if limit <= 0 || limit > len(isCandidate) {
+		limit = len(isCandidate)
+	}
+	if limit == 0 {
+		return result
+	}
+
+	ticks := map[pose]int{start: 0}
+	queue := []pose{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if opponent, found := board.firstInLineFrom(myState, current, maxDistance); found && isCandidate[opponent.Id] {
+			if _, seen := result[opponent.Id]; !seen {
+				result[opponent.Id] = ticks[current]
+				if len(result) == limit {
+					return result
+				}
+			}
+		}
+		if ticks[current] >= maxTicks {
+			continue
+		}
+		for _, move := range []cloudbowl.Move{cloudbowl.Forward, cloudbowl.TurnLeft, cloudbowl.TurnRight} {
+			next, moved := board.nextPose(myState, current, move)
+			if !moved {
+				continue
+			}
+			if _, seen := ticks[next]; seen {
+				continue
+			}
+			ticks[next] = ticks[current] + 1
+			queue = append(queue, next)
+		}
+	}
+	return result
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
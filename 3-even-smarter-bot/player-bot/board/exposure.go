@@ -0,0 +1,67 @@
+package board
+
+import (
+	"math"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+var EXPOSURE_HORIZON = 4       // how many ticks ahead to look for opponents who could line up on a square
+var EXPOSURE_DECAY = 0.5       // how much less an opponent counts for each extra tick they would need to line up on a square
+var OPEN_SQUARE_EXPOSURE = 0.1 // how much each square someone could throw at a square from counts, whether or not anyone is there yet
+var MIN_FIRING_LANES = 2       // how many directions a square we retreat to must let us throw in
+
+// how exposed a square is to opponents, and how much use it is to throw from
+type SquareRating struct {
+	Exposure float64 // lower is safer, see rateSquare
+	Lanes    int     // how many directions a throw from the square would travel at least one square in
+}
+
+// rates a square. Every opponent who could line up on it within EXPOSURE_HORIZON ticks counts, the sooner the more,
+// and so does every square within maxDistance in the same row or column, since that is where anyone would have to stand
+// to throw at it. A wall on one side takes away a quarter of those squares, so corners and edges are safer.
+func (board Board) rateSquare(threats ThreatMap, x int, y int, maxDistance int) (result SquareRating) {
+	for _, threat := range threats.ThreatsAt(x, y) {
+		result.Exposure += math.Pow(EXPOSURE_DECAY, float64(threat.Ticks-1))
+	}
+	for _, direction := range cloudbowl.Directions {
+		squares := len(board.squaresInFront(cloudbowl.PlayerState{X: x, Y: y, Direction: direction}, maxDistance))
+		result.Exposure += OPEN_SQUARE_EXPOSURE * float64(squares)
+		if squares > 0 {
+			result.Lanes++
+		}
+	}
+	return result
+}
+
+// finds the least exposed square that nobody else is standing on and that we could throw from in at least
+// MIN_FIRING_LANES directions, preferring the nearest when several are as safe as each other. found is false if there
+// is no such square.
+func (board Board) FindSafestSquare(myState cloudbowl.PlayerState, maxDistance int) (safest Square, found bool) {
+	threats := board.ThreatMap(myState, maxDistance, EXPOSURE_HORIZON)
+	bestExposure, bestDistance := math.Inf(1), math.Inf(1)
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
+			if board.isOccupiedByOpponent(myState, x, y) {
+				continue
+			}
+			rating := board.rateSquare(threats, x, y, maxDistance)
+			if rating.Lanes < MIN_FIRING_LANES {
+				continue
+			}
+			distance := calculateDistance(myState.X, myState.Y, x, y)
+			if rating.Exposure < bestExposure || (rating.Exposure == bestExposure && distance < bestDistance) {
+				safest, bestExposure, bestDistance, found = Square{X: x, Y: y}, rating.Exposure, distance, true
+			}
+		}
+	}
+	return safest, found
+}
+
+// finds the shortest sequence of moves that takes the player to the square, facing whichever way they end up facing.
+// Like PlanPathToFiringPosition, other players are treated as not moving. found is false if the square can't be reached.
+func (board Board) PlanPathTo(myState cloudbowl.PlayerState, square Square) (moves []cloudbowl.Move, found bool) {
+	return board.planPath(myState, func(current pose) bool {
+		return current.X == square.X && current.Y == square.Y
+	}, nil)
+}
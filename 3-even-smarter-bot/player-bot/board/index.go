@@ -0,0 +1,131 @@
+package board
+
+import (
+	"math"
+	"sort"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// buckets the players by row and by column so that spatial queries only look at the rows near the point of interest,
+// instead of every square on the board
+type Index struct {
+	rows    [][]*cloudbowl.PlayerState // rows[y] holds the players in that row, sorted by x
+	columns [][]*cloudbowl.PlayerState // columns[x] holds the players in that column, sorted by y
+}
+
+func NewIndex(width int, height int, players map[string]*cloudbowl.PlayerState) Index {
+	index := Index{rows: make([][]*cloudbowl.PlayerState, height), columns: make([][]*cloudbowl.PlayerState, width)}
+	for _, player := range players {
+		index.rows[player.Y] = append(index.rows[player.Y], player)
+		index.columns[player.X] = append(index.columns[player.X], player)
+	}
+	for y := range index.rows {
+		row := index.rows[y]
+		sort.Slice(row, func(i, j int) bool { return row[i].X < row[j].X })
+	}
+	for x := range index.columns {
+		column := index.columns[x]
+		sort.Slice(column, func(i, j int) bool { return column[i].Y < column[j].Y })
+	}
+	return index
+}
+
+// lists the players in row y, from west to east
+func (index Index) InRow(y int) []cloudbowl.PlayerState {
+	if y < 0 || y >= len(index.rows) {
+		return nil
+	}
+	return dereference(index.rows[y])
+}
+
+// lists the players in column x, from north to south
+func (index Index) InColumn(x int) []cloudbowl.PlayerState {
+	if x < 0 || x >= len(index.columns) {
+		return nil
+	}
+	return dereference(index.columns[x])
+}
+
+// lists the players within the radius of x,y (by straight line distance), nearest first
+func (index Index) WithinRadius(x int, y int, radius float64, include func(player cloudbowl.PlayerState) bool) []cloudbowl.PlayerState {
+	var found []*cloudbowl.PlayerState
+	reach := int(math.Floor(radius))
+	for dy := -reach; dy <= reach; dy++ {
+		row := y + dy
+		if row < 0 || row >= len(index.rows) {
+			continue
+		}
+		dx := int(math.Floor(math.Sqrt(radius*radius - float64(dy*dy))))
+		for _, player := range playersBetween(index.rows[row], x-dx, x+dx) {
+			if include == nil || include(*player) {
+				found = append(found, player)
+			}
+		}
+	}
+	sortByDistance(found, x, y)
+	return dereference(found)
+}
+
+// finds up to k players closest to x,y (by straight line distance), nearest first. Players at the same distance are
+// ordered west to east, then north to south. Rows are searched outwards from y and the search stops as soon as no
+// unsearched row could hold anyone closer. Nothing is returned unless k is at least 1.
+func (index Index) NearestK(x int, y int, k int, include func(player cloudbowl.PlayerState) bool) []cloudbowl.PlayerState {
+	if k <= 0 {
+		return nil
+	}
+	var found []*cloudbowl.PlayerState
+	for dy := 0; dy < len(index.rows); dy++ {
+		if len(found) >= k && dy*dy > distanceSquared(found[k-1], x, y) {
+			break
+		}
+		for i, row := range [2]int{y - dy, y + dy} {
+			if row < 0 || row >= len(index.rows) || (dy == 0 && i == 1) {
+				continue // off the board, or our own row which was already searched
+			}
+			for _, player := range index.rows[row] {
+				if include == nil || include(*player) {
+					found = append(found, player)
+				}
+			}
+		}
+		sortByDistance(found, x, y)
+		if len(found) > k {
+			found = found[:k]
+		}
+	}
+	return dereference(found)
+}
+
+// the players in a sorted row whose x is from min to max inclusive
+func playersBetween(row []*cloudbowl.PlayerState, min int, max int) []*cloudbowl.PlayerState {
+	start := sort.Search(len(row), func(i int) bool { return row[i].X >= min })
+	end := sort.Search(len(row), func(i int) bool { return row[i].X > max })
+	return row[start:end]
+}
+
+func sortByDistance(players []*cloudbowl.PlayerState, x int, y int) {
+	sort.Slice(players, func(i, j int) bool {
+		di := distanceSquared(players[i], x, y)
+		dj := distanceSquared(players[j], x, y)
+		if di != dj {
+			return di < dj
+		}
+		if players[i].X != players[j].X {
+			return players[i].X < players[j].X
+		}
+		return players[i].Y < players[j].Y
+	})
+}
+
+func distanceSquared(player *cloudbowl.PlayerState, x int, y int) int {
+	return (player.X-x)*(player.X-x) + (player.Y-y)*(player.Y-y)
+}
+
+func dereference(players []*cloudbowl.PlayerState) []cloudbowl.PlayerState {
+	result := make([]cloudbowl.PlayerState, len(players))
+	for i, player := range players {
+		result[i] = *player
+	}
+	return result
+}
@@ -0,0 +1,208 @@
+package board
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// the arenas the index is benchmarked on, e.g.
+//
+//	go test ./board -run '^$' -bench Index
+var BENCHMARK_SIZES = [][2]int{{7, 5}, {20, 15}, {100, 100}, {500, 500}}
+var BENCHMARK_DENSITY = 0.02 // fraction of the squares that have a player on them
+var BENCHMARK_RADIUS = 5.0   // radius to use for the within-radius queries
+
+// scanning the whole board for every player gets slow on the larger arenas, so only check a sample of them
+var CHECKED_PLAYERS = 50
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard) // the board logs every query it answers
+	os.Exit(m.Run())
+}
+
+// builds a board with players placed on random squares, facing random directions
+func randomBoard(width int, height int, density float64, seed int64) Board {
+	rng := rand.New(rand.NewSource(seed))
+	count := int(float64(width*height) * density)
+	if count < 2 {
+		count = 2
+	}
+	players := make(map[string]cloudbowl.PlayerState, count)
+	for i, square := range rng.Perm(width * height)[:count] {
+		players[fmt.Sprintf("http://player-%v", i)] = cloudbowl.PlayerState{
+			X:         square % width,
+			Y:         square / width,
+			Direction: cloudbowl.Directions[rng.Intn(len(cloudbowl.Directions))],
+		}
+	}
+	return New(width, height, players)
+}
+
+func TestIndexMatchesScan(t *testing.T) {
+	for _, size := range BENCHMARK_SIZES {
+		board := randomBoard(size[0], size[1], BENCHMARK_DENSITY, 1)
+		ids := make([]string, 0, len(board.Players))
+		for id := range board.Players {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		if len(ids) > CHECKED_PLAYERS {
+			ids = ids[:CHECKED_PLAYERS]
+		}
+		for _, id := range ids {
+			me := *board.Players[id]
+			notMe := func(player cloudbowl.PlayerState) bool { return player.Id != me.Id }
+			if got, want := playerIds(board.Index.NearestK(me.X, me.Y, 3, notMe)), playerIds(scanNearestK(board, me, 3)); got != want {
+				t.Errorf("%vx%v: nearest 3 to %v: index found %v, scan found %v", size[0], size[1], id, got, want)
+			}
+			if got, want := playerIds(board.Index.NearestK(me.X, me.Y, 1, notMe)), scanNearest(board, me).Id; got != want {
+				t.Errorf("%vx%v: nearest to %v: index found %v, scan found %v", size[0], size[1], id, got, want)
+			}
+			if got, want := playerIds(board.Index.WithinRadius(me.X, me.Y, BENCHMARK_RADIUS, notMe)), playerIds(scanWithinRadius(board, me, BENCHMARK_RADIUS)); got != want {
+				t.Errorf("%vx%v: within %v of %v: index found %v, scan found %v", size[0], size[1], BENCHMARK_RADIUS, id, got, want)
+			}
+			if got, want := playerIds(board.Index.InRow(me.Y)), playerIds(scanRow(board, me.Y)); got != want {
+				t.Errorf("%vx%v: row %v: index found %v, scan found %v", size[0], size[1], me.Y, got, want)
+			}
+			if got, want := playerIds(board.Index.InColumn(me.X)), playerIds(scanColumn(board, me.X)); got != want {
+				t.Errorf("%vx%v: column %v: index found %v, scan found %v", size[0], size[1], me.X, got, want)
+			}
+		}
+	}
+}
+
+func TestNearestKWithoutRoom(t *testing.T) {
+	board := randomBoard(7, 5, 0.5, 1)
+	for _, k := range []int{0, -1} {
+		if got := board.Index.NearestK(3, 2, k, nil); got != nil {
+			t.Errorf("NearestK(k = %v) = %v, want nil", k, got)
+		}
+	}
+}
+
+func BenchmarkIndex(b *testing.B) {
+	for _, size := range BENCHMARK_SIZES {
+		board := randomBoard(size[0], size[1], BENCHMARK_DENSITY, 1)
+		me := *board.Players["http://player-0"]
+		notMe := func(player cloudbowl.PlayerState) bool { return player.Id != me.Id }
+		queries := []struct {
+			name  string
+			scan  func()
+			index func()
+		}{
+			{"nearest", func() { scanNearest(board, me) }, func() { board.Index.NearestK(me.X, me.Y, 1, notMe) }},
+			{"nearest-3", func() { scanNearestK(board, me, 3) }, func() { board.Index.NearestK(me.X, me.Y, 3, notMe) }},
+			{"within-radius", func() { scanWithinRadius(board, me, BENCHMARK_RADIUS) }, func() { board.Index.WithinRadius(me.X, me.Y, BENCHMARK_RADIUS, notMe) }},
+			{"same-row", func() { scanRow(board, me.Y) }, func() { board.Index.InRow(me.Y) }},
+			{"same-column", func() { scanColumn(board, me.X) }, func() { board.Index.InColumn(me.X) }},
+		}
+		for _, query := range queries {
+			for _, approach := range []struct {
+				name string
+				run  func()
+			}{{"scan", query.scan}, {"index", query.index}} {
+				b.Run(fmt.Sprintf("%vx%v/%v/%v", size[0], size[1], query.name, approach.name), func(b *testing.B) {
+					for i := 0; i < b.N; i++ {
+						approach.run()
+					}
+				})
+			}
+		}
+		b.Run(fmt.Sprintf("%vx%v/build", size[0], size[1]), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				NewIndex(size[0], size[1], board.Players)
+			}
+		})
+	}
+}
+
+func playerIds(players []cloudbowl.PlayerState) string {
+	result := make([]string, len(players))
+	for i, player := range players {
+		result[i] = player.Id
+	}
+	return strings.Join(result, ",")
+}
+
+// the whole board scan FindClosestOpponent used to find the nearest opponent as the crow flies
+func scanNearest(board Board, me cloudbowl.PlayerState) cloudbowl.PlayerState {
+	closest := cloudbowl.PlayerState{}
+	closestDistance := -1
+	for x := range board.Squares {
+		for y := range board.Squares[x] {
+			if board.IsSquareOccupied(x, y) && board.Squares[x][y].Id != me.Id {
+				distance := (x-me.X)*(x-me.X) + (y-me.Y)*(y-me.Y)
+				if closestDistance == -1 || distance < closestDistance {
+					closestDistance = distance
+					closest = *board.Squares[x][y]
+				}
+			}
+		}
+	}
+	return closest
+}
+
+// a whole board scan keeping the k nearest, ordered the same way as the index orders them
+func scanNearestK(board Board, me cloudbowl.PlayerState, k int) (result []cloudbowl.PlayerState) {
+	distance := func(p cloudbowl.PlayerState) int { return (p.X-me.X)*(p.X-me.X) + (p.Y-me.Y)*(p.Y-me.Y) }
+	for x := range board.Squares {
+		for y := range board.Squares[x] {
+			if !board.IsSquareOccupied(x, y) || board.Squares[x][y].Id == me.Id {
+				continue
+			}
+			// squares are visited west to east then north to south, so an equal distance never displaces an earlier find
+			player := *board.Squares[x][y]
+			i := len(result)
+			for i > 0 && distance(player) < distance(result[i-1]) {
+				i--
+			}
+			if i < k {
+				result = append(result[:i], append([]cloudbowl.PlayerState{player}, result[i:]...)...)
+				if len(result) > k {
+					result = result[:k]
+				}
+			}
+		}
+	}
+	return result
+}
+
+func scanWithinRadius(board Board, me cloudbowl.PlayerState, radius float64) (result []cloudbowl.PlayerState) {
+	distance := func(p cloudbowl.PlayerState) int { return (p.X-me.X)*(p.X-me.X) + (p.Y-me.Y)*(p.Y-me.Y) }
+	for x := range board.Squares {
+		for y := range board.Squares[x] {
+			if board.IsSquareOccupied(x, y) && board.Squares[x][y].Id != me.Id && float64(distance(*board.Squares[x][y])) <= radius*radius {
+				result = append(result, *board.Squares[x][y])
+			}
+		}
+	}
+	// a stable sort keeps the west to east, north to south order the squares were visited in for equal distances
+	sort.SliceStable(result, func(i, j int) bool { return distance(result[i]) < distance(result[j]) })
+	return result
+}
+
+func scanRow(board Board, y int) (result []cloudbowl.PlayerState) {
+	for x := range board.Squares {
+		if board.IsSquareOccupied(x, y) {
+			result = append(result, *board.Squares[x][y])
+		}
+	}
+	return result
+}
+
+func scanColumn(board Board, x int) (result []cloudbowl.PlayerState) {
+	for y := range board.Squares[x] {
+		if board.IsSquareOccupied(x, y) {
+			result = append(result, *board.Squares[x][y])
+		}
+	}
+	return result
+}
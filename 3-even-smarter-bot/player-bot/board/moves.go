@@ -0,0 +1,29 @@
+package board
+
+import (
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// works out the player's state after the move, assuming nobody else moves. changed is false when the move would be
+// wasted: walking into a wall or another player, or throwing with nobody in range.
+func (board Board) Next(state cloudbowl.PlayerState, move cloudbowl.Move, maxDistance int) (next cloudbowl.PlayerState, changed bool) {
+	next = state
+	switch move {
+	case cloudbowl.TurnLeft:
+		next.Direction = state.Direction.RotateLeft()
+	case cloudbowl.TurnRight:
+		next.Direction = state.Direction.RotateRight()
+	case cloudbowl.Forward:
+		if cloudbowl.IsForwardBlocked(board, state) {
+			return state, false
+		}
+		dx, dy := state.Direction.Step()
+		next.X += dx
+		next.Y += dy
+	case cloudbowl.Throw:
+		return state, board.IsThereAnOpponentInFrontOfMe(state, maxDistance)
+	default:
+		return state, false
+	}
+	return next, next.Direction != state.Direction || next.X != state.X || next.Y != state.Y
+}
@@ -0,0 +1,123 @@
+package board
+
+import (
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// a position and facing a player could be in, which is what the planner searches over
+type pose struct {
+	X         int
+	Y         int
+	Direction cloudbowl.Direction
+}
+
+// finds the shortest sequence of moves that leaves the player facing an opponent within maxDistance squares, counting
+// turns as moves as well as steps forward. Other players are treated as not moving, so their squares can't be walked
+// through. An empty plan means we can already throw, found is false if no opponent can be lined up at all.
+func (board Board) PlanPathToFiringPosition(myState cloudbowl.PlayerState, maxDistance int) (moves []cloudbowl.Move, found bool) {
+	return board.PlanPathToFiringPositionAgainst(myState, maxDistance, func(cloudbowl.PlayerState) bool { return true })
+}
+
+// the same as PlanPathToFiringPosition, but only opponents for which isTarget returns true count.
+// A throw hits the first player in line, so a position where someone else is in the way doesn't count either.
+func (board Board) PlanPathToFiringPositionAgainst(myState cloudbowl.PlayerState, maxDistance int, isTarget func(opponent cloudbowl.PlayerState) bool) (moves []cloudbowl.Move, found bool) {
+	return board.planPath(myState, func(current pose) bool {
+		return board.canHitTargetFrom(myState, current, maxDistance, isTarget)
+	}, nil)
+}
+
+// finds the shortest sequence of moves that leaves the player in a pose for which isGoal returns true, only stepping
+// into squares for which canEnter returns true, or any square if canEnter is nil
+func (board Board) planPath(myState cloudbowl.PlayerState, isGoal func(current pose) bool, canEnter func(square Square) bool) (moves []cloudbowl.Move, found bool) {
+	start := pose{X: myState.X, Y: myState.Y, Direction: myState.Direction}
+	if !start.Direction.Valid() || !board.IsOnBoard(start.X, start.Y) {
+		return nil, false
+	}
+	// breadth first search, every move costs one tick so the first firing position reached is the closest
+	type step struct {
+		from pose
+		move cloudbowl.Move
+	}
+	previous := map[pose]step{start: {}}
+	queue := []pose{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if isGoal(current) {
+			for current != start {
+				moves = append([]cloudbowl.Move{previous[current].move}, moves...)
+				current = previous[current].from
+			}
+			return moves, true
+		}
+		for _, move := range []cloudbowl.Move{cloudbowl.Forward, cloudbowl.TurnLeft, cloudbowl.TurnRight} {
+			next, moved := board.nextPose(myState, current, move)
+			if !moved || (move == cloudbowl.Forward && canEnter != nil && !canEnter(Square{X: next.X, Y: next.Y})) {
+				continue
+			}
+			if _, seen := previous[next]; seen {
+				continue
+			}
+			previous[next] = step{from: current, move: move}
+			queue = append(queue, next)
+		}
+	}
+	return nil, false
+}
+
+// applies a move to a pose, moved is false if a forward move would be blocked by a wall or another player
+func (board Board) nextPose(myState cloudbowl.PlayerState, current pose, move cloudbowl.Move) (next pose, moved bool) {
+	next = current
+	switch move {
+	case cloudbowl.TurnLeft:
+		next.Direction = current.Direction.RotateLeft()
+	case cloudbowl.TurnRight:
+		next.Direction = current.Direction.RotateRight()
+	case cloudbowl.Forward:
+		dx, dy := current.Direction.Step()
+		next.X += dx
+		next.Y += dy
+		if !board.IsOnBoard(next.X, next.Y) || board.isOccupiedByOpponent(myState, next.X, next.Y) {
+			return current, false
+		}
+	default:
+		return current, false
+	}
+	return next, true
+}
+
+// determines if the first player in line from the pose, within the max distance, is one of our targets
+func (board Board) canHitTargetFrom(myState cloudbowl.PlayerState, from pose, maxDistance int, isTarget func(opponent cloudbowl.PlayerState) bool) bool {
+	opponent, found := board.firstInLineFrom(myState, from, maxDistance)
+	return found && isTarget(opponent)
+}
+
+// finds the player a throw from the pose would hit, within the max distance
+func (board Board) firstInLineFrom(myState cloudbowl.PlayerState, from pose, maxDistance int) (opponent cloudbowl.PlayerState, found bool) {
+	if id, found := cloudbowl.FirstPlayerInLine(withoutMe{board, myState}, from.X, from.Y, from.Direction, maxDistance); found {
+		return *board.Players[id], true
+	}
+	return opponent, false
+}
+
+// the board as it looks once we have moved off our starting square
+type withoutMe struct {
+	board   Board
+	myState cloudbowl.PlayerState
+}
+
+func (squares withoutMe) IsOnBoard(x int, y int) bool {
+	return squares.board.IsOnBoard(x, y)
+}
+
+func (squares withoutMe) PlayerAt(x int, y int) (id string, found bool) {
+	if x == squares.myState.X && y == squares.myState.Y {
+		return "", false
+	}
+	return squares.board.PlayerAt(x, y)
+}
+
+// our own starting square is empty once we have moved off it, so only other players get in the way
+func (board Board) isOccupiedByOpponent(myState cloudbowl.PlayerState, x int, y int) bool {
+	return board.IsSquareOccupied(x, y) && !(x == myState.X && y == myState.Y)
+}
@@ -0,0 +1,62 @@
+package board
+
+import (
+	"log"
+	"math"
+	"sort"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+var NEMESIS_WEIGHT = 1.0           // how much each hit an opponent has landed on us counts towards wanting revenge
+var REVENGE_WEIGHT = 3.0           // how much an opponent who has only just hit us counts, fading the longer ago it was
+var REVENGE_MEMORY = 0.8           // how much of the revenge weight is left for each tick since they last hit us
+var REVENGE_DISTANCE_PENALTY = 0.5 // how much each tick it would take to line up on an opponent counts against them
+
+// how an opponent has been attacking us
+type Grudge struct {
+	Hits       float64 // how many times they have hit us, an uncertain hit counts for part of one
+	TicksSince int     // how many ticks ago they last hit us, 0 meaning in the update we just got, see LONG_AGO
+}
+
+// the TicksSince to use when there is no telling when they last hit us, so that it doesn't count as a recent hit
+const LONG_AGO = math.MaxInt32
+
+// picks the opponent we most want to get back at: the one who has hit us the most, with extra weight for anyone who
+// has hit us recently, less the ticks it would take to line up on them. Only the candidates are considered, or every
+// opponent if candidates is nil, so it can be combined with e.g. HighScoringOpponents. found is false if none of them
+// has hit us.
+func (board Board) FindRevengeTarget(myState cloudbowl.PlayerState, grudges map[string]Grudge, candidates []cloudbowl.PlayerState, maxDistance int) (target cloudbowl.PlayerState, found bool) {
+	var ids []string
+	if candidates == nil {
+		for id := range board.Players {
+			ids = append(ids, id)
+		}
+	} else {
+		for _, candidate := range candidates {
+			ids = append(ids, candidate.Id)
+		}
+	}
+	sort.Strings(ids) // so ties always go the same way
+
+	distances := board.ActionDistances(myState, maxDistance)
+	bestScore := math.Inf(-1)
+	for _, id := range ids {
+		grudge, holding := grudges[id]
+		opponent, onBoard := board.Player(id)
+		if !holding || !onBoard || id == myState.Id {
+			continue
+		}
+		score := NEMESIS_WEIGHT*grudge.Hits + REVENGE_WEIGHT*math.Pow(REVENGE_MEMORY, float64(grudge.TicksSince))
+		score -= REVENGE_DISTANCE_PENALTY * float64(estimateActionDistance(myState, opponent, distances))
+		if score > bestScore {
+			bestScore = score
+			target = opponent
+			found = true
+		}
+	}
+	if found {
+		log.Printf("returning revenge target: %v, who has hit me %.1f times, last %v ticks ago", target, grudges[target.Id].Hits, grudges[target.Id].TicksSince)
+	}
+	return target, found
+}
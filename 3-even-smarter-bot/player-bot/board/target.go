@@ -0,0 +1,144 @@
+package board
+
+import (
+	"log"
+	"math"
+	"sort"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// how much each thing we know about an opponent counts towards going after them. Each weight multiplies one feature
+// of the opponent and the opponent with the highest total is the target, so negative weights count against them.
+type TargetWeights struct {
+	ActionDistance float64 // per tick it would take to line up on them
+	Score          float64 // per point they have
+	Rank           float64 // per place they are below the top of the leaderboard
+	Threat         float64 // if they could hit us with their next move
+	WasHit         float64 // if they were hit in the last tick
+	FacingAway     float64 // if they are facing away from us, so can't hit us without turning first
+	Grudge         float64 // per hit they have landed on us
+}
+
+var DEFAULT_TARGET_WEIGHTS = TargetWeights{
+	ActionDistance: -1,
+	Score:          0.05,
+	Rank:           -0.1,
+	Threat:         1,
+	WasHit:         0,
+	FacingAway:     0.5,
+	Grudge:         0.5,
+}
+
+// a policy for picking which opponent to go after. The leaderboard is sorted from highest to lowest score, or nil if
+// there isn't one, and grudges holds everyone who has hit us. found is false if there is nobody to go after.
+type TargetSelector interface {
+	Select(board Board, myState cloudbowl.PlayerState, leaderboard []cloudbowl.PlayerState, threats ThreatMap, grudges map[string]Grudge) (target cloudbowl.PlayerState, found bool)
+}
+
+// picks which opponent to go after by scoring every one of them
+type WeightedTargetSelector struct {
+	Weights     TargetWeights
+	MaxDistance int
+}
+
+func NewWeightedTargetSelector(weights TargetWeights, maxDistance int) WeightedTargetSelector {
+	return WeightedTargetSelector{Weights: weights, MaxDistance: maxDistance}
+}
+
+// scores every opponent on the board and returns the best one
+func (selector WeightedTargetSelector) Select(board Board, myState cloudbowl.PlayerState, leaderboard []cloudbowl.PlayerState, threats ThreatMap, grudges map[string]Grudge) (target cloudbowl.PlayerState, found bool) {
+	scores := selector.ScoreAll(board, myState, leaderboard, threats, grudges)
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids) // so ties always go the same way
+	best := math.Inf(-1)
+	for _, id := range ids {
+		if scores[id] > best {
+			best = scores[id]
+			target = *board.Players[id]
+			found = true
+		}
+	}
+	if found {
+		log.Printf("selected target: %v, with a score of %.2f", target, best)
+	}
+	return target, found
+}
+
+// scores every opponent on the board, indexed by their Id
+func (selector WeightedTargetSelector) ScoreAll(board Board, myState cloudbowl.PlayerState, leaderboard []cloudbowl.PlayerState, threats ThreatMap, grudges map[string]Grudge) map[string]float64 {
+	weights := selector.Weights
+	distances := board.ActionDistances(myState, selector.MaxDistance)
+	ranks := make(map[string]int, len(leaderboard))
+	for i, player := range leaderboard {
+		ranks[player.Id] = i
+	}
+	threatening := make(map[string]bool)
+	for _, threat := range threats.ThreatsAt(myState.X, myState.Y) {
+		if threat.Ticks <= 1 {
+			threatening[threat.Opponent.Id] = true
+		}
+	}
+
+	result := make(map[string]float64, len(board.Players))
+	for id, opponent := range board.Players {
+		if id == myState.Id {
+			continue
+		}
+		score := weights.ActionDistance*float64(estimateActionDistance(myState, *opponent, distances)) + weights.Score*float64(opponent.Score)
+		if rank, ranked := ranks[id]; ranked {
+			score += weights.Rank * float64(rank)
+		}
+		if threatening[id] {
+			score += weights.Threat
+		}
+		if opponent.WasHit {
+			score += weights.WasHit
+		}
+		if isFacingAway(*opponent, myState) {
+			score += weights.FacingAway
+		}
+		score += weights.Grudge * grudges[id].Hits
+		result[id] = score
+	}
+	return result
+}
+
+// the policy the bot started out with: whoever we could throw at soonest, unless we are leading, in which case only the
+// high scorers are worth going after, starting with any of them who have been hitting us if SeekRevenge is set
+type LeaderboardTargetSelector struct {
+	Percentile  float64 // what makes a player a high scorer, see FindClosestHighScoringOpponent
+	SeekRevenge bool
+	MaxDistance int
+}
+
+func (selector LeaderboardTargetSelector) Select(board Board, myState cloudbowl.PlayerState, leaderboard []cloudbowl.PlayerState, threats ThreatMap, grudges map[string]Grudge) (target cloudbowl.PlayerState, found bool) {
+	if board.NumberOfPlayers < 2 {
+		return target, false
+	}
+	// with nobody else in the top percentile there are no high scorers to go after, so go after everyone instead
+	if len(leaderboard) > 0 && leaderboard[0].Id == myState.Id {
+		log.Printf("I am the leader, targeting high scoring players only")
+		if highScoringOpponents := board.HighScoringOpponents(myState, leaderboard, selector.Percentile); len(highScoringOpponents) > 0 {
+			if selector.SeekRevenge {
+				if target, found := board.FindRevengeTarget(myState, grudges, highScoringOpponents, selector.MaxDistance); found {
+					return target, true
+				}
+			}
+			if target = board.FindClosestHighScoringOpponent(myState, leaderboard, selector.Percentile, selector.MaxDistance); target.Id != "" {
+				return target, true
+			}
+		}
+	}
+	return board.FindClosestOpponent(myState, selector.MaxDistance), true
+}
+
+// determines if the opponent would have to turn before they could throw at us
+func isFacingAway(opponent cloudbowl.PlayerState, myState cloudbowl.PlayerState) bool {
+	bearing := cloudbowl.BearingBetween(opponent.X, opponent.Y, myState.X, myState.Y)
+	relative := opponent.Direction.Bearing().EighthsClockwiseTo(bearing)
+	return relative > 1 && relative < 7 // not straight ahead of them or diagonally ahead
+}
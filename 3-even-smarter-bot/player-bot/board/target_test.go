@@ -0,0 +1,84 @@
+package board
+
+import (
+	"testing"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+func TestScoreAll(t *testing.T) {
+	me := cloudbowl.PlayerState{Id: "me", X: 0, Y: 0, Direction: cloudbowl.East}
+	players := map[string]cloudbowl.PlayerState{
+		"me":     me,
+		"ahead":  {X: 2, Y: 0, Direction: cloudbowl.West, Score: 3},   // in our line of fire, and we are in theirs
+		"below":  {X: 0, Y: 2, Direction: cloudbowl.South, Score: -2}, // one turn away, facing away from us
+		"corner": {X: 6, Y: 4, Direction: cloudbowl.North, Score: 5, WasHit: true},
+	}
+	board := New(7, 5, players)
+	leaderboard := []cloudbowl.PlayerState{{Id: "corner"}, {Id: "ahead"}, {Id: "me"}, {Id: "below"}}
+	threats := board.ThreatMap(me, 3, 2)
+	grudges := map[string]Grudge{"below": {Hits: 2.5}}
+	distances := board.ActionDistances(me, 3)
+
+	tests := []struct {
+		name    string
+		weights TargetWeights
+		want    map[string]float64
+	}{
+		{"action distance", TargetWeights{ActionDistance: -1}, map[string]float64{
+			"ahead": 0, "below": -1, "corner": -float64(distances["corner"]),
+		}},
+		{"score", TargetWeights{Score: 2}, map[string]float64{"ahead": 6, "below": -4, "corner": 10}},
+		{"rank", TargetWeights{Rank: -1}, map[string]float64{"ahead": -1, "below": -3, "corner": 0}},
+		{"threat", TargetWeights{Threat: 1}, map[string]float64{"ahead": 1, "below": 0, "corner": 0}},
+		{"was hit", TargetWeights{WasHit: 1}, map[string]float64{"ahead": 0, "below": 0, "corner": 1}},
+		{"facing away", TargetWeights{FacingAway: 1}, map[string]float64{"ahead": 0, "below": 1, "corner": 0}},
+		{"grudge", TargetWeights{Grudge: 2}, map[string]float64{"ahead": 0, "below": 5, "corner": 0}},
+		{"combined", TargetWeights{Score: 1, Threat: 1, Grudge: 1}, map[string]float64{"ahead": 4, "below": 0.5, "corner": 5}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := NewWeightedTargetSelector(test.weights, 3).ScoreAll(board, me, leaderboard, threats, grudges)
+			if len(got) != len(test.want) {
+				t.Errorf("ScoreAll() = %v, want %v", got, test.want)
+			}
+			for id, want := range test.want {
+				if score, scored := got[id]; !scored || score != want {
+					t.Errorf("ScoreAll()[%v] = %v, want %v", id, score, want)
+				}
+			}
+		})
+	}
+}
+
+func TestScoreAllWithoutLeaderboard(t *testing.T) {
+	me := cloudbowl.PlayerState{Id: "me", X: 0, Y: 0, Direction: cloudbowl.East}
+	board := New(7, 5, map[string]cloudbowl.PlayerState{"me": me, "a": {X: 3, Y: 3, Direction: cloudbowl.North}})
+	got := NewWeightedTargetSelector(TargetWeights{Rank: -1}, 3).ScoreAll(board, me, nil, board.ThreatMap(me, 3, 2), nil)
+	if got["a"] != 0 {
+		t.Errorf("ScoreAll()[a] = %v, want 0 with no leaderboard to rank them on", got["a"])
+	}
+}
+
+func TestWeightedTargetSelectorSelect(t *testing.T) {
+	me := cloudbowl.PlayerState{Id: "me", X: 3, Y: 2, Direction: cloudbowl.North}
+	board := New(7, 5, map[string]cloudbowl.PlayerState{
+		"me": me,
+		"b":  {X: 0, Y: 0, Direction: cloudbowl.East, Score: 1},
+		"a":  {X: 6, Y: 4, Direction: cloudbowl.East, Score: 1},
+		"c":  {X: 6, Y: 0, Direction: cloudbowl.East, Score: 4},
+	})
+	selector := NewWeightedTargetSelector(TargetWeights{Score: 1}, 3)
+	if target, found := selector.Select(board, me, nil, board.ThreatMap(me, 3, 2), nil); !found || target.Id != "c" {
+		t.Errorf("Select() = %v, %v, want the highest scorer c", target.Id, found)
+	}
+	// a and b tie, so the lowest Id wins
+	selector = NewWeightedTargetSelector(TargetWeights{}, 3)
+	if target, found := selector.Select(board, me, nil, board.ThreatMap(me, 3, 2), nil); !found || target.Id != "a" {
+		t.Errorf("Select() = %v, %v, want a", target.Id, found)
+	}
+	alone := New(7, 5, map[string]cloudbowl.PlayerState{"me": me})
+	if target, found := selector.Select(alone, me, nil, alone.ThreatMap(me, 3, 2), nil); found {
+		t.Errorf("Select() = %v, want nobody on an empty board", target.Id)
+	}
+}
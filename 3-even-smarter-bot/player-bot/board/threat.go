@@ -0,0 +1,128 @@
+package board
+
+import (
+	"sort"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// an opponent who could hit a square, and how soon
+type Threat struct {
+	Opponent cloudbowl.PlayerState
+	Ticks    int // 1 means the opponent can hit the square with their very next move
+}
+
+// which opponents could hit each square of the board within a number of ticks, given where they are, the way
+// they are facing and how far they can throw
+type ThreatMap struct {
+	Horizon int
+	squares [][][]Threat
+}
+
+// builds the threat map for the provided player, looking up to horizon ticks ahead. An opponent needs one tick to throw,
+// so a horizon of 1 only covers the squares already in their line of fire, a horizon of 2 also covers the squares they
+// could line up on with one move first, and so on. A throw stops at the first player it reaches, except for us since
+// we are the one who is going to move.
+func (board Board) ThreatMap(myState cloudbowl.PlayerState, maxDistance int, horizon int) ThreatMap {
+	threats := ThreatMap{Horizon: horizon, squares: make([][][]Threat, board.Width)}
+	for x := range threats.squares {
+		threats.squares[x] = make([][]Threat, board.Height)
+	}
+	for x := range board.Squares {
+		for y := range board.Squares[x] {
+			if !board.IsSquareOccupied(x, y) || board.Squares[x][y].Id == myState.Id {
+				continue
+			}
+			opponent := *board.Squares[x][y]
+			for square, ticks := range board.ticksUntilOpponentCanHit(myState, opponent, maxDistance, horizon) {
+				threats.squares[square.X][square.Y] = append(threats.squares[square.X][square.Y], Threat{Opponent: opponent, Ticks: ticks})
+			}
+		}
+	}
+	for x := range threats.squares {
+		for y := range threats.squares[x] {
+			sort.Slice(threats.squares[x][y], func(i, j int) bool {
+				return threats.squares[x][y][i].Ticks < threats.squares[x][y][j].Ticks
+			})
+		}
+	}
+	return threats
+}
+
+// lists the opponents that could hit the square within the map's horizon, soonest first
+func (threats ThreatMap) ThreatsAt(x int, y int) []Threat {
+	if x < 0 || x >= len(threats.squares) || y < 0 || y >= len(threats.squares[x]) {
+		return nil
+	}
+	return threats.squares[x][y]
+}
+
+// counts the opponents that could hit the square within the provided number of ticks
+func (threats ThreatMap) CountThreatsWithin(x int, y int, ticks int) (result int) {
+	for _, threat := range threats.ThreatsAt(x, y) {
+		if threat.Ticks <= ticks {
+			result++
+		}
+	}
+	return result
+}
+
+// determines if any opponent could hit the square with their next move
+func (threats ThreatMap) IsInLineOfFire(x int, y int) bool {
+	return threats.CountThreatsWithin(x, y, 1) > 0
+}
+
+// searches the moves the opponent could make before throwing, returning the fewest ticks it would take them to hit
+// each square they could hit within the horizon
+func (board Board) ticksUntilOpponentCanHit(myState cloudbowl.PlayerState, opponent cloudbowl.PlayerState, maxDistance int, horizon int) map[Square]int {
+	result := make(map[Square]int)
+	start := pose{X: opponent.X, Y: opponent.Y, Direction: opponent.Direction}
+	if !start.Direction.Valid() {
+		return result
+	}
+	movesTaken := map[pose]int{start: 0}
+	queue := []pose{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		ticks := movesTaken[current] + 1 // the throw itself takes a tick
+		if ticks > horizon {
+			continue
+		}
+		for _, square := range board.lineOfFire(myState, opponent, current, maxDistance) {
+			if existing, seen := result[square]; !seen || ticks < existing {
+				result[square] = ticks
+			}
+		}
+		for _, move := range []cloudbowl.Move{cloudbowl.Forward, cloudbowl.TurnLeft, cloudbowl.TurnRight} {
+			// the opponent can't walk through anyone else, and we are in their way too until we move
+			next, moved := board.nextPose(opponent, current, move)
+			if !moved {
+				continue
+			}
+			if _, seen := movesTaken[next]; seen {
+				continue
+			}
+			movesTaken[next] = movesTaken[current] + 1
+			queue = append(queue, next)
+		}
+	}
+	return result
+}
+
+// lists the squares a throw from the pose could land on, stopping after the first player other than us or the thrower
+func (board Board) lineOfFire(myState cloudbowl.PlayerState, thrower cloudbowl.PlayerState, from pose, maxDistance int) (result []Square) {
+	dx, dy := from.Direction.Step()
+	for i := 1; i <= maxDistance; i++ {
+		x := from.X + dx*i
+		y := from.Y + dy*i
+		if !board.IsOnBoard(x, y) {
+			break
+		}
+		result = append(result, Square{X: x, Y: y})
+		if board.IsSquareOccupied(x, y) && !(x == myState.X && y == myState.Y) && !(x == thrower.X && y == thrower.Y) {
+			break
+		}
+	}
+	return result
+}
@@ -0,0 +1,146 @@
+package board
+
+import (
+	"math"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// how likely a player is to make each move in the next tick, whatever is left over is the chance they do nothing
+type MovePrior struct {
+	Forward   float64
+	TurnLeft  float64
+	TurnRight float64
+	Throw     float64
+}
+
+// what we expect from an opponent we know nothing about yet
+var DEFAULT_MOVE_PRIOR = MovePrior{Forward: 0.3, TurnLeft: 0.15, TurnRight: 0.15, Throw: 0.3}
+
+var REPOSITION_DISCOUNT = 0.5 // how much a throw we could line up for next tick is worth compared to one we can make now
+
+// looks up how likely each opponent who could matter to EvaluateThrow is to make each move, indexed by their Id. A lookup
+// can mean a trip to wherever the players' histories are kept, so it is done once per decision rather than for every
+// throw weighed up.
+func (board Board) ThrowPriors(myState cloudbowl.PlayerState, maxDistance int, lookup func(opponent cloudbowl.PlayerState) MovePrior) map[string]MovePrior {
+	include := func(player cloudbowl.PlayerState) bool { return player.Id != myState.Id }
+	// a throw reaches one square further than an opponent stepping into its path, and we may step first
+	opponents := board.Index.WithinRadius(myState.X, myState.Y, float64(maxDistance+2), include)
+	result := make(map[string]MovePrior, len(opponents))
+	for _, opponent := range opponents {
+		result[opponent.Id] = lookup(opponent)
+	}
+	return result
+}
+
+// the chance of a throw made now hitting each opponent, indexed by their Id. Throws land after everyone has moved, so the
+// opponent in front of us may have walked out of the way by then and someone else may have walked in. A throw stops at
+// the first player it reaches, so an opponent only counts as hit if nobody ended up in front of them. priors holds how
+// likely each opponent is to make each move, anyone left out is expected to behave like DEFAULT_MOVE_PRIOR.
+func (board Board) ThrowOutcomes(myState cloudbowl.PlayerState, maxDistance int, priors map[string]MovePrior) map[string]float64 {
+	line := board.squaresInFront(myState, maxDistance)
+	if len(line) == 0 {
+		return nil
+	}
+	distances := make(map[Square]int, len(line))
+	for i, square := range line {
+		distances[square] = i
+	}
+
+	// only turning doesn't move anyone, so an opponent either stays where they are or takes a step forward
+	occupants := make([]map[string]float64, len(line))
+	occupy := func(square Square, id string, probability float64) {
+		if i, inLine := distances[square]; inLine && probability > 0 {
+			if occupants[i] == nil {
+				occupants[i] = make(map[string]float64)
+			}
+			occupants[i][id] += probability
+		}
+	}
+	for _, opponent := range board.opponentsBesideLine(myState, line) {
+		prior, known := priors[opponent.Id]
+		if !known {
+			prior = DEFAULT_MOVE_PRIOR
+		}
+		forward := math.Min(math.Max(prior.Forward, 0), 1)
+		if cloudbowl.IsForwardBlocked(board, opponent) {
+			forward = 0
+		}
+		dx, dy := opponent.Direction.Step()
+		occupy(Square{X: opponent.X, Y: opponent.Y}, opponent.Id, 1-forward)
+		occupy(Square{X: opponent.X + dx, Y: opponent.Y + dy}, opponent.Id, forward)
+	}
+
+	result := make(map[string]float64)
+	reaches := 1.0 // the chance the throw gets this far without hitting anyone
+	for _, square := range occupants {
+		empty, total := 1.0, 0.0
+		for _, probability := range square {
+			empty *= 1 - probability
+			total += probability
+		}
+		// two players can't end up in the same square, so when more than one might, share it out between them
+		for id, probability := range square {
+			result[id] += reaches * (1 - empty) * probability / total
+		}
+		reaches *= empty
+	}
+	return result
+}
+
+// lists the opponents who are on the line of squares in front of the player or could step onto it: anyone in the same
+// row or column as the line, or either side of it, no more than one square past either end
+func (board Board) opponentsBesideLine(myState cloudbowl.PlayerState, line []Square) (result []cloudbowl.PlayerState) {
+	_, dy := myState.Direction.Step()
+	horizontal := dy == 0
+	first, last := line[0], line[len(line)-1]
+	for offset := -1; offset <= 1; offset++ {
+		var players []cloudbowl.PlayerState
+		from, to := first.Y, last.Y
+		if horizontal {
+			players = board.Index.InRow(myState.Y + offset)
+			from, to = first.X, last.X
+		} else {
+			players = board.Index.InColumn(myState.X + offset)
+		}
+		if from > to {
+			from, to = to, from
+		}
+		for _, player := range players {
+			along := player.Y
+			if horizontal {
+				along = player.X
+			}
+			if player.Id != myState.Id && along >= from-1 && along <= to+1 {
+				result = append(result, player)
+			}
+		}
+	}
+	return result
+}
+
+// the chance of a throw made now hitting anyone at all
+func (board Board) HitProbability(myState cloudbowl.PlayerState, maxDistance int, priors map[string]MovePrior) (result float64) {
+	for _, probability := range board.ThrowOutcomes(myState, maxDistance, priors) {
+		result += probability
+	}
+	return result
+}
+
+// weighs up the points a throw made now is expected to earn against the best move we could make to line up a throw
+// for next tick instead. reposition is the best of those moves, or empty if we can't move at all.
+func (board Board) EvaluateThrow(myState cloudbowl.PlayerState, maxDistance int, priors map[string]MovePrior) (throwValue float64, repositionValue float64, reposition cloudbowl.Move) {
+	throwValue = board.HitProbability(myState, maxDistance, priors)
+	repositionValue = -1
+	for _, move := range cloudbowl.LegalMovements(board, myState) {
+		next, _ := board.Next(myState, move, maxDistance)
+		value := REPOSITION_DISCOUNT * board.HitProbability(next, maxDistance, priors)
+		if value > repositionValue {
+			repositionValue, reposition = value, move
+		}
+	}
+	if repositionValue < 0 {
+		repositionValue = 0
+	}
+	return throwValue, repositionValue, reposition
+}
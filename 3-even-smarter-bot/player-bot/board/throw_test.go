@@ -0,0 +1,143 @@
+package board
+
+import (
+	"math"
+	"testing"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+var STAY = MovePrior{}
+var WALK = MovePrior{Forward: 1}
+
+func TestThrowOutcomes(t *testing.T) {
+	me := cloudbowl.PlayerState{X: 0, Y: 2, Direction: cloudbowl.East}
+	tests := []struct {
+		name    string
+		me      cloudbowl.PlayerState // defaults to me
+		players map[string]cloudbowl.PlayerState
+		priors  map[string]MovePrior
+		want    map[string]float64
+	}{
+		{
+			name:    "nobody in line",
+			players: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 0, Direction: cloudbowl.South}},
+			priors:  map[string]MovePrior{"a": STAY},
+			want:    map[string]float64{},
+		},
+		{
+			name:    "standing still",
+			players: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.North}},
+			priors:  map[string]MovePrior{"a": STAY},
+			want:    map[string]float64{"a": 1},
+		},
+		{
+			name:    "walking out of the way",
+			players: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.North}},
+			priors:  map[string]MovePrior{"a": {Forward: 0.4}},
+			want:    map[string]float64{"a": 0.6},
+		},
+		{
+			name:    "walking along the line of fire",
+			players: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.East}},
+			priors:  map[string]MovePrior{"a": WALK},
+			want:    map[string]float64{"a": 1},
+		},
+		{
+			name:    "walking into range",
+			players: map[string]cloudbowl.PlayerState{"a": {X: 4, Y: 2, Direction: cloudbowl.West}},
+			priors:  map[string]MovePrior{"a": {Forward: 0.5}},
+			want:    map[string]float64{"a": 0.5},
+		},
+		{
+			name:    "walking into the line of fire",
+			players: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 3, Direction: cloudbowl.North}},
+			priors:  map[string]MovePrior{"a": {Forward: 0.5}},
+			want:    map[string]float64{"a": 0.5},
+		},
+		{
+			name:    "a wall in the way",
+			me:      cloudbowl.PlayerState{X: 0, Y: 0, Direction: cloudbowl.East},
+			players: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 0, Direction: cloudbowl.North}},
+			priors:  map[string]MovePrior{"a": WALK},
+			want:    map[string]float64{"a": 1},
+		},
+		{
+			name: "occluded",
+			players: map[string]cloudbowl.PlayerState{
+				"a": {X: 1, Y: 2, Direction: cloudbowl.North},
+				"b": {X: 3, Y: 2, Direction: cloudbowl.North},
+			},
+			priors: map[string]MovePrior{"a": STAY, "b": STAY},
+			want:   map[string]float64{"a": 1},
+		},
+		{
+			name: "partly occluded",
+			players: map[string]cloudbowl.PlayerState{
+				"a": {X: 1, Y: 2, Direction: cloudbowl.North},
+				"b": {X: 3, Y: 2, Direction: cloudbowl.North},
+			},
+			priors: map[string]MovePrior{"a": {Forward: 0.5}, "b": STAY},
+			want:   map[string]float64{"a": 0.5, "b": 0.5},
+		},
+		{
+			name: "two heading for the same square",
+			players: map[string]cloudbowl.PlayerState{
+				"a": {X: 2, Y: 1, Direction: cloudbowl.South},
+				"b": {X: 2, Y: 3, Direction: cloudbowl.North},
+			},
+			priors: map[string]MovePrior{"a": WALK, "b": WALK},
+			want:   map[string]float64{"a": 0.5, "b": 0.5},
+		},
+		{
+			name:    "unknown opponents behave like the default",
+			players: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.North}},
+			priors:  nil,
+			want:    map[string]float64{"a": 1 - DEFAULT_MOVE_PRIOR.Forward},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			myState := test.me
+			if myState.Direction == "" {
+				myState = me
+			}
+			myState.Id = "me"
+			players := map[string]cloudbowl.PlayerState{"me": myState}
+			for id, player := range test.players {
+				players[id] = player
+			}
+			got := New(7, 5, players).ThrowOutcomes(myState, 3, test.priors)
+			for id, want := range test.want {
+				if math.Abs(got[id]-want) > 1e-9 {
+					t.Errorf("ThrowOutcomes()[%v] = %v, want %v", id, got[id], want)
+				}
+			}
+			for id, probability := range got {
+				if _, wanted := test.want[id]; !wanted && probability != 0 {
+					t.Errorf("ThrowOutcomes()[%v] = %v, want 0", id, probability)
+				}
+			}
+		})
+	}
+}
+
+func TestThrowPriorsLooksUpEachOpponentOnce(t *testing.T) {
+	me := cloudbowl.PlayerState{Id: "me", X: 0, Y: 2, Direction: cloudbowl.East}
+	board := New(7, 5, map[string]cloudbowl.PlayerState{
+		"me":   me,
+		"near": {X: 2, Y: 2, Direction: cloudbowl.North},
+		"far":  {X: 6, Y: 4, Direction: cloudbowl.North},
+	})
+	lookups := make(map[string]int)
+	priors := board.ThrowPriors(me, 3, func(opponent cloudbowl.PlayerState) MovePrior {
+		lookups[opponent.Id]++
+		return STAY
+	})
+	if lookups["near"] != 1 || lookups["far"] != 0 || lookups["me"] != 0 {
+		t.Errorf("looked up %v, want only near, once", lookups)
+	}
+	if throwValue, _, _ := board.EvaluateThrow(me, 3, priors); throwValue != 1 {
+		t.Errorf("EvaluateThrow() = %v, want a certain hit on someone standing still", throwValue)
+	}
+}
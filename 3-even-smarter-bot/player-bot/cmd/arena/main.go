@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"player-bot/engine"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// runs a whole Cloudbowl match locally, sending every bot the same arena update the real game would each tick, e.g.
+//
+//	go run ./cmd/arena -bots http://localhost:8081,http://localhost:8082,http://localhost:8083
+func main() {
+	bots := flag.String("bots", "", "comma separated list of bot URLs to play against each other")
+	width := flag.Int("width", 7, "width of the arena")
+	height := flag.Int("height", 5, "height of the arena")
+	timeout := flag.Duration("timeout", 500*time.Millisecond, "how long to wait for each bot to respond with a move")
+	interval := flag.Duration("interval", time.Second, "minimum time between ticks")
+	rounds := flag.Int("rounds", 0, "number of ticks to play, 0 keeps playing until the process is stopped")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed used to place the players at the start of the match")
+	addr := flag.String("addr", "", "optional address to serve the current arena state on, e.g. :8080")
+	flag.Parse()
+
+	var urls []string
+	for _, url := range strings.Split(*bots, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	if len(urls) == 0 {
+		log.Fatalf("no bots configured, use -bots to provide their URLs")
+	}
+
+	state, err := engine.NewGame(*width, *height, urls, rand.New(rand.NewSource(*seed)))
+	if err != nil {
+		log.Fatalf("failed to create arena: %v", err)
+	}
+	arena := &arena{state: state, client: &http.Client{}}
+	if *addr != "" {
+		http.HandleFunc("/", arena.handler)
+		go func() {
+			log.Printf("serving arena state on %v", *addr)
+			log.Fatalf("http listen error: %v", http.ListenAndServe(*addr, nil))
+		}()
+	}
+
+	log.Printf("starting a %vx%v match between %v bots with seed %v", *width, *height, len(urls), *seed)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for round := 1; *rounds == 0 || round <= *rounds; round++ {
+		if err := arena.tick(*timeout); err != nil {
+			log.Fatalf("round %v failed: %v", round, err)
+		}
+		log.Printf("round %v: %v", round, arena.summary())
+		<-ticker.C
+	}
+}
+
+type arena struct {
+	mu     sync.RWMutex
+	state  cloudbowl.ArenaUpdate
+	client *http.Client
+}
+
+// asks every bot for its move in parallel and then applies all of the moves at once
+func (arena *arena) tick(timeout time.Duration) error {
+	state := arena.current()
+	moves := make(map[string]cloudbowl.Move, len(state.Arena.State))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for href := range state.Arena.State {
+		wg.Add(1)
+		go func(href string) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			move, err := arena.requestMove(ctx, href, state)
+			if err != nil {
+				log.Printf("WARN: no move from %v this round: %v", href, err)
+				return
+			}
+			mu.Lock()
+			moves[href] = move
+			mu.Unlock()
+		}(href)
+	}
+	wg.Wait()
+
+	next, err := engine.Next(state, moves)
+	if err != nil {
+		return err
+	}
+	arena.mu.Lock()
+	arena.state = next
+	arena.mu.Unlock()
+	return nil
+}
+
+// sends the arena update to a single bot, with the self link pointing at that bot, and returns the move it chose
+func (arena *arena) requestMove(ctx context.Context, href string, state cloudbowl.ArenaUpdate) (cloudbowl.Move, error) {
+	state.Links.Self.Href = href
+	// the game doesn't send player ids, bots work them out from the keys of the state
+	players := make(map[string]cloudbowl.PlayerState, len(state.Arena.State))
+	for id, player := range state.Arena.State {
+		player.Id = ""
+		players[id] = player
+	}
+	state.Arena.State = players
+	body, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, href, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := arena.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %v", resp.Status)
+	}
+	move, err := io.ReadAll(io.LimitReader(resp.Body, 16))
+	if err != nil {
+		return "", err
+	}
+	return cloudbowl.ParseMove(strings.TrimSpace(string(move)))
+}
+
+func (arena *arena) current() cloudbowl.ArenaUpdate {
+	arena.mu.RLock()
+	defer arena.mu.RUnlock()
+	return arena.state
+}
+
+// lists the players from highest to lowest score
+func (arena *arena) summary() string {
+	state := arena.current()
+	hrefs := make([]string, 0, len(state.Arena.State))
+	for href := range state.Arena.State {
+		hrefs = append(hrefs, href)
+	}
+	sort.Slice(hrefs, func(i, j int) bool {
+		return state.Arena.State[hrefs[i]].Score > state.Arena.State[hrefs[j]].Score
+	})
+	var result []string
+	for _, href := range hrefs {
+		player := state.Arena.State[href]
+		result = append(result, fmt.Sprintf("%v=%v (x:%v y:%v %v)", href, player.Score, player.X, player.Y, player.Direction))
+	}
+	return strings.Join(result, ", ")
+}
+
+func (arena *arena) handler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(arena.current()); err != nil {
+		log.Printf("WARN: failed to encode arena state: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"player-bot/strategy"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// feeds a recording made with RECORD_FILE back through a strategy and prints every round where it now decides
+// differently, e.g.
+//
+//	go run ./cmd/replay -strategy leaderboard-aware recording.jsonl
+//
+// Recordings don't include the leaderboard the bot saw at the time, so it is rebuilt from each arena update.
+func main() {
+	name := flag.String("strategy", "leaderboard-aware", fmt.Sprintf("strategy to replay the recording through, one of %v", strategy.Names()))
+	seed := flag.Int64("seed", 1, "seed for strategies that make random decisions")
+	verbose := flag.Bool("v", false, "show the strategy's own logging")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: replay [flags] recording.jsonl")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		logger.Fatalf("failed to open recording: %v", err)
+	}
+	defer file.Close()
+	records, err := cloudbowl.ReadRecording(file)
+	if err != nil {
+		logger.Fatalf("failed to read recording: %v", err)
+	}
+	botStrategy, err := strategy.New(*name, strategy.Options{Rng: rand.New(rand.NewSource(*seed)), Leaderboard: strategy.LeaderboardFromArena})
+	if err != nil {
+		logger.Fatalf("failed to create strategy: %v", err)
+	}
+
+	differences := 0
+	for i, record := range records {
+		move := botStrategy.Play(record.Update)
+		if move == record.Move {
+			continue
+		}
+		differences++
+		me := record.Update.Arena.State[record.Update.Links.Self.Href]
+		fmt.Printf("round %v at %v: recorded %v, now %v (at x:%v y:%v facing %v with a score of %v)\n",
+			i+1, record.Timestamp.Format("15:04:05.000"), record.Move, move, me.X, me.Y, me.Direction, me.Score)
+	}
+	fmt.Printf("%v of %v rounds differ\n", differences, len(records))
+	if differences > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"player-bot/board"
+	"player-bot/engine"
+	"player-bot/strategy"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// plays many seeded matches between the bot strategies in-process and reports how well each of them did, e.g.
+//
+//	go run ./cmd/tournament -matches 5000 -sizes 7x5,20x15 -players 3,8
+func main() {
+	matches := flag.Int("matches", 1000, "number of matches to play")
+	rounds := flag.Int("rounds", 100, "number of ticks in each match")
+	seed := flag.Int64("seed", 1, "seed for the first match, each following match uses the next seed")
+	sizes := flag.String("sizes", "7x5,12x9,20x15", "comma separated list of arena sizes to cycle through")
+	players := flag.String("players", "2,4,8", "comma separated list of player counts to cycle through")
+	names := flag.String("strategies", "random,nearest-target,leaderboard-aware", fmt.Sprintf("comma separated list of strategies to compare, from %v", strategy.Names()))
+	parallel := flag.Int("parallel", runtime.NumCPU(), "number of matches to play at the same time")
+	budget := flag.Duration("budget", 20*time.Millisecond, "time strategies that search may spend on each move, only used if -iterations is 0")
+	iterations := flag.Int("iterations", 1000, "steps strategies that search may take on each move, or 0 to search for -budget instead, which makes the results depend on how busy the machine is")
+	movementMode := flag.String("movement", strategy.BEARING_MOVEMENT, fmt.Sprintf("how strategies head for their target, %q or %q", strategy.BEARING_MOVEMENT, strategy.ALIGNMENT_MOVEMENT))
+	targeting := flag.String("targeting", strategy.WEIGHTED_TARGETING, fmt.Sprintf("how strategies pick their target, %q or %q", strategy.WEIGHTED_TARGETING, strategy.LEADERBOARD_TARGETING))
+	flag.Parse()
+
+	// the strategies log every decision they make, which is far too noisy for thousands of matches
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	log.SetOutput(io.Discard)
+
+	dimensions, err := parseSizes(*sizes)
+	if err != nil {
+		logger.Fatalf("invalid -sizes: %v", err)
+	}
+	playerCounts, err := parseInts(*players)
+	if err != nil {
+		logger.Fatalf("invalid -players: %v", err)
+	}
+	movement, err := strategy.NewMovement(*movementMode)
+	if err != nil {
+		logger.Fatalf("invalid -movement: %v", err)
+	}
+	selector, err := strategy.NewTargetSelector(*targeting, board.DEFAULT_TARGET_WEIGHTS)
+	if err != nil {
+		logger.Fatalf("invalid -targeting: %v", err)
+	}
+	var contestants []string
+	for _, name := range strings.Split(*names, ",") {
+		if _, err := strategy.New(name, strategy.Options{}); err != nil {
+			logger.Fatalf("invalid -strategies: %v", err)
+		}
+		contestants = append(contestants, name)
+	}
+
+	results := make([][]seat, *matches)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < *parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				size := dimensions[i%len(dimensions)]
+				count := playerCounts[(i/len(dimensions))%len(playerCounts)]
+				result, err := playMatch(*seed+int64(i), size[0], size[1], count, *rounds, contestants, *budget, *iterations, movement, selector)
+				if err != nil {
+					logger.Fatalf("match %v failed: %v", i, err)
+				}
+				results[i] = result
+			}
+		}()
+	}
+	for i := 0; i < *matches; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	report(os.Stdout, contestants, results)
+}
+
+// how one seat in a match finished
+type seat struct {
+	strategy string
+	score    int
+	rank     int // 1 is the winner, players on the same score share a rank
+}
+
+func playMatch(seed int64, width int, height int, count int, rounds int, contestants []string, budget time.Duration, iterations int, movement strategy.Movement, selector board.TargetSelector) ([]seat, error) {
+	var err error
+	rng := rand.New(rand.NewSource(seed))
+	hrefs := make([]string, count)
+	players := make(map[string]strategy.Strategy, count)
+	names := make(map[string]string, count)
+	offset := rng.Intn(len(contestants)) // rotate the seats so every strategy meets every other
+	for i := range hrefs {
+		name := contestants[(offset+i)%len(contestants)]
+		// forward moves are resolved in href order, so the hrefs mustn't give away which strategy is in each seat
+		hrefs[i] = fmt.Sprintf("http://player-%v", i)
+		// each seat gets its own instance of the strategy, sharing the match's rng
+		players[hrefs[i]], err = strategy.New(name, strategy.Options{Rng: rng, Leaderboard: strategy.LeaderboardFromArena, Budget: budget, Iterations: iterations, Movement: movement, TargetSelector: selector})
+		if err != nil {
+			return nil, err
+		}
+		names[hrefs[i]] = name
+	}
+	sort.Strings(hrefs) // strategies share the match's rng, so always ask them in the same order
+
+	state, err := engine.NewGame(width, height, hrefs, rng)
+	if err != nil {
+		return nil, err
+	}
+	for round := 0; round < rounds; round++ {
+		moves := make(map[string]cloudbowl.Move, count)
+		for _, href := range hrefs {
+			input := state
+			input.Links.Self.Href = href
+			moves[href] = players[href].Play(input)
+		}
+		if state, err = engine.Next(state, moves); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]seat, 0, count)
+	for _, href := range hrefs {
+		rank := 1
+		for _, other := range state.Arena.State {
+			if other.Score > state.Arena.State[href].Score {
+				rank++
+			}
+		}
+		result = append(result, seat{strategy: names[href], score: state.Arena.State[href].Score, rank: rank})
+	}
+	return result, nil
+}
+
+func report(w io.Writer, contestants []string, results [][]seat) {
+	maxRank := 0
+	byStrategy := make(map[string][]seat)
+	for _, match := range results {
+		for _, seat := range match {
+			byStrategy[seat.strategy] = append(byStrategy[seat.strategy], seat)
+			if seat.rank > maxRank {
+				maxRank = seat.rank
+			}
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := "strategy\tseats\tmean score\t95% CI\twin rate\t95% CI\t"
+	for rank := 1; rank <= maxRank; rank++ {
+		header += fmt.Sprintf("#%v\t", rank)
+	}
+	fmt.Fprintln(tw, header)
+	for _, name := range contestants {
+		seats := byStrategy[name]
+		n := float64(len(seats))
+		if n == 0 {
+			fmt.Fprintf(tw, "%v\t0\t\n", name)
+			continue
+		}
+		sum, wins := 0.0, 0.0
+		ranks := make([]int, maxRank+1)
+		for _, seat := range seats {
+			sum += float64(seat.score)
+			ranks[seat.rank]++
+			if seat.rank == 1 {
+				wins++
+			}
+		}
+		mean := sum / n
+		variance := 0.0
+		for _, seat := range seats {
+			variance += math.Pow(float64(seat.score)-mean, 2)
+		}
+		if n > 1 {
+			variance /= n - 1
+		}
+		winRate := wins / n
+		line := fmt.Sprintf("%v\t%v\t%.2f\t±%.2f\t%.1f%%\t±%.1f%%\t", name, len(seats), mean,
+			1.96*math.Sqrt(variance/n), 100*winRate, 100*1.96*math.Sqrt(winRate*(1-winRate)/n))
+		for rank := 1; rank <= maxRank; rank++ {
+			line += fmt.Sprintf("%.1f%%\t", 100*float64(ranks[rank])/n)
+		}
+		fmt.Fprintln(tw, line)
+	}
+	tw.Flush()
+}
+
+func parseSizes(value string) (result [][2]int, err error) {
+	for _, size := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(size), "x")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q is not of the form WIDTHxHEIGHT", size)
+		}
+		dimensions, err := parseInts(parts[0] + "," + parts[1])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, [2]int{dimensions[0], dimensions[1]})
+	}
+	return result, nil
+}
+
+func parseInts(value string) (result []int, err error) {
+	for _, part := range strings.Split(value, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if i <= 0 {
+			return nil, fmt.Errorf("%v must be greater than zero", i)
+		}
+		result = append(result, i)
+	}
+	return result, nil
+}
@@ -0,0 +1,156 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// mirrors the rules of the Cloudbowl arena so bots can be exercised without the live game
+var HIT_REWARD = 1  // points gained by a player whose throw hits someone
+var HIT_PENALTY = 1 // points lost by a player who is hit
+
+// creates the starting state for a match, placing each player on a random empty square facing a random direction
+func NewGame(width int, height int, players []string, rng *rand.Rand) (result cloudbowl.ArenaUpdate, err error) {
+	if width <= 0 || height <= 0 {
+		return result, fmt.Errorf("invalid arena dimensions %vx%v", width, height)
+	}
+	if len(players) > width*height {
+		return result, fmt.Errorf("cannot fit %v players into a %vx%v arena", len(players), width, height)
+	}
+	result.Arena.Dimensions = []int{width, height}
+	result.Arena.State = make(map[string]cloudbowl.PlayerState, len(players))
+	squares := rng.Perm(width * height)
+	for i, href := range players {
+		if _, exists := result.Arena.State[href]; exists {
+			return result, fmt.Errorf("duplicate player %v", href)
+		}
+		result.Arena.State[href] = cloudbowl.PlayerState{
+			Id:        href,
+			X:         squares[i] % width,
+			Y:         squares[i] / width,
+			Direction: cloudbowl.Directions[rng.Intn(len(cloudbowl.Directions))],
+		}
+	}
+	return result, nil
+}
+
+// applies one move per player to the provided state and returns the next state.
+// Players without a move (e.g. because their bot timed out) stay where they are. The provided state is not modified.
+//
+// Turns are applied first, then forward moves in player order (a forward move into a wall or an occupied square
+// does nothing), and finally throws are resolved against the positions everyone ended up in. A throw hits the first
+// player within cloudbowl.MAX_THROW_DISTANCE squares in the direction the thrower is facing.
+func Next(state cloudbowl.ArenaUpdate, moves map[string]cloudbowl.Move) (result cloudbowl.ArenaUpdate, err error) {
+	if len(state.Arena.Dimensions) != 2 {
+		return result, fmt.Errorf("invalid arena dimensions %v", state.Arena.Dimensions)
+	}
+	width := state.Arena.Dimensions[0]
+	height := state.Arena.Dimensions[1]
+	for href, move := range moves {
+		if _, exists := state.Arena.State[href]; !exists {
+			return result, fmt.Errorf("move %q provided for unknown player %v", move, href)
+		}
+		if !move.Valid() {
+			return result, fmt.Errorf("invalid move %q for player %v", move, href)
+		}
+	}
+
+	result = state
+	result.Arena.Dimensions = []int{width, height}
+	result.Arena.State = make(map[string]cloudbowl.PlayerState, len(state.Arena.State))
+	occupied := squares{width: width, height: height, players: make(map[[2]int]string, len(state.Arena.State))}
+	hrefs := make([]string, 0, len(state.Arena.State))
+	for href, player := range state.Arena.State {
+		player.Id = href
+		player.WasHit = false
+		result.Arena.State[href] = player
+		occupied.players[[2]int{player.X, player.Y}] = href
+		hrefs = append(hrefs, href)
+	}
+	// map iteration order is random, so process players in a fixed order to keep matches reproducible
+	sort.Strings(hrefs)
+
+	for _, href := range hrefs {
+		player := result.Arena.State[href]
+		switch moves[href] {
+		case cloudbowl.TurnLeft:
+			player.Direction = player.Direction.RotateLeft()
+		case cloudbowl.TurnRight:
+			player.Direction = player.Direction.RotateRight()
+		}
+		result.Arena.State[href] = player
+	}
+
+	for _, href := range hrefs {
+		if moves[href] != cloudbowl.Forward {
+			continue
+		}
+		player := result.Arena.State[href]
+		if cloudbowl.IsForwardBlocked(occupied, player) {
+			continue // walking into a wall or another player
+		}
+		dx, dy := player.Direction.Step()
+		delete(occupied.players, [2]int{player.X, player.Y})
+		player.X += dx
+		player.Y += dy
+		occupied.players[[2]int{player.X, player.Y}] = href
+		result.Arena.State[href] = player
+	}
+
+	for _, href := range hrefs {
+		if moves[href] != cloudbowl.Throw {
+			continue
+		}
+		thrower := result.Arena.State[href]
+		target, hit := cloudbowl.FirstPlayerInLine(occupied, thrower.X, thrower.Y, thrower.Direction, cloudbowl.MAX_THROW_DISTANCE)
+		if !hit {
+			continue
+		}
+		victim := result.Arena.State[target]
+		victim.WasHit = true
+		victim.Score -= HIT_PENALTY
+		result.Arena.State[target] = victim
+		thrower.Score += HIT_REWARD
+		result.Arena.State[href] = thrower
+	}
+	return result, nil
+}
+
+// works out who each player would hit if they threw from where they are now, indexed by the thrower.
+// Players with nobody in range are left out.
+func Targets(state cloudbowl.ArenaUpdate) map[string]string {
+	result := make(map[string]string)
+	if len(state.Arena.Dimensions) != 2 {
+		return result
+	}
+	occupied := squares{width: state.Arena.Dimensions[0], height: state.Arena.Dimensions[1], players: make(map[[2]int]string, len(state.Arena.State))}
+	for href, player := range state.Arena.State {
+		occupied.players[[2]int{player.X, player.Y}] = href
+	}
+	for href, player := range state.Arena.State {
+		if target, hit := cloudbowl.FirstPlayerInLine(occupied, player.X, player.Y, player.Direction, cloudbowl.MAX_THROW_DISTANCE); hit {
+			result[href] = target
+		}
+	}
+	return result
+}
+
+// who is standing where while a tick is being worked out, indexed by x,y, so the rules can be applied without going
+// through every player for each square
+type squares struct {
+	width   int
+	height  int
+	players map[[2]int]string
+}
+
+func (squares squares) IsOnBoard(x int, y int) bool {
+	return x >= 0 && x < squares.width && y >= 0 && y < squares.height
+}
+
+func (squares squares) PlayerAt(x int, y int) (href string, found bool) {
+	href, found = squares.players[[2]int{x, y}]
+	return href, found
+}
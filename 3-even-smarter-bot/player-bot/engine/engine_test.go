@@ -0,0 +1,255 @@
+package engine
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name    string
+		players map[string]cloudbowl.PlayerState
+		moves   map[string]cloudbowl.Move
+		want    map[string]cloudbowl.PlayerState
+	}{
+		{
+			name:    "turning left",
+			players: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.North}},
+			moves:   map[string]cloudbowl.Move{"a": cloudbowl.TurnLeft},
+			want:    map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.West}},
+		},
+		{
+			name:    "turning right",
+			players: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.West}},
+			moves:   map[string]cloudbowl.Move{"a": cloudbowl.TurnRight},
+			want:    map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.North}},
+		},
+		{
+			name:    "moving forward",
+			players: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.North}},
+			moves:   map[string]cloudbowl.Move{"a": cloudbowl.Forward},
+			want:    map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 1, Direction: cloudbowl.North}},
+		},
+		{
+			name:    "walking into a wall",
+			players: map[string]cloudbowl.PlayerState{"a": {X: 4, Y: 0, Direction: cloudbowl.East}},
+			moves:   map[string]cloudbowl.Move{"a": cloudbowl.Forward},
+			want:    map[string]cloudbowl.PlayerState{"a": {X: 4, Y: 0, Direction: cloudbowl.East}},
+		},
+		{
+			name: "walking into another player",
+			players: map[string]cloudbowl.PlayerState{
+				"a": {X: 1, Y: 1, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 1, Direction: cloudbowl.South},
+			},
+			moves: map[string]cloudbowl.Move{"a": cloudbowl.Forward},
+			want: map[string]cloudbowl.PlayerState{
+				"a": {X: 1, Y: 1, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 1, Direction: cloudbowl.South},
+			},
+		},
+		{
+			name: "following a player who moves first",
+			players: map[string]cloudbowl.PlayerState{
+				"a": {X: 2, Y: 1, Direction: cloudbowl.East},
+				"b": {X: 1, Y: 1, Direction: cloudbowl.East},
+			},
+			moves: map[string]cloudbowl.Move{"a": cloudbowl.Forward, "b": cloudbowl.Forward},
+			want: map[string]cloudbowl.PlayerState{
+				"a": {X: 3, Y: 1, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 1, Direction: cloudbowl.East},
+			},
+		},
+		{
+			name: "two players stepping into the same square",
+			players: map[string]cloudbowl.PlayerState{
+				"a": {X: 1, Y: 1, Direction: cloudbowl.East},
+				"b": {X: 3, Y: 1, Direction: cloudbowl.West},
+			},
+			moves: map[string]cloudbowl.Move{"a": cloudbowl.Forward, "b": cloudbowl.Forward},
+			want: map[string]cloudbowl.PlayerState{
+				"a": {X: 2, Y: 1, Direction: cloudbowl.East},
+				"b": {X: 3, Y: 1, Direction: cloudbowl.West},
+			},
+		},
+		{
+			name: "throwing at the maximum distance",
+			players: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East, Score: 5},
+				"b": {X: 3, Y: 0, Direction: cloudbowl.North, Score: 5},
+			},
+			moves: map[string]cloudbowl.Move{"a": cloudbowl.Throw},
+			want: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East, Score: 6},
+				"b": {X: 3, Y: 0, Direction: cloudbowl.North, Score: 4, WasHit: true},
+			},
+		},
+		{
+			name: "throwing out of range",
+			players: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 4, Y: 0, Direction: cloudbowl.North},
+			},
+			moves: map[string]cloudbowl.Move{"a": cloudbowl.Throw},
+			want: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 4, Y: 0, Direction: cloudbowl.North},
+			},
+		},
+		{
+			name: "throwing at a wall",
+			players: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.North},
+				"b": {X: 1, Y: 0, Direction: cloudbowl.North},
+			},
+			moves: map[string]cloudbowl.Move{"a": cloudbowl.Throw},
+			want: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.North},
+				"b": {X: 1, Y: 0, Direction: cloudbowl.North},
+			},
+		},
+		{
+			name: "throwing at a player standing behind another",
+			players: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 2, Direction: cloudbowl.East},
+				"b": {X: 1, Y: 2, Direction: cloudbowl.North},
+				"c": {X: 2, Y: 2, Direction: cloudbowl.North},
+			},
+			moves: map[string]cloudbowl.Move{"a": cloudbowl.Throw},
+			want: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 2, Direction: cloudbowl.East, Score: 1},
+				"b": {X: 1, Y: 2, Direction: cloudbowl.North, Score: -1, WasHit: true},
+				"c": {X: 2, Y: 2, Direction: cloudbowl.North},
+			},
+		},
+		{
+			name: "throwing at a player who walks out of the way",
+			players: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 2, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 2, Direction: cloudbowl.North},
+			},
+			moves: map[string]cloudbowl.Move{"a": cloudbowl.Throw, "b": cloudbowl.Forward},
+			want: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 2, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 1, Direction: cloudbowl.North},
+			},
+		},
+		{
+			name: "throwing at a player who walks into the way",
+			players: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 2, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 3, Direction: cloudbowl.North},
+			},
+			moves: map[string]cloudbowl.Move{"a": cloudbowl.Throw, "b": cloudbowl.Forward},
+			want: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 2, Direction: cloudbowl.East, Score: 1},
+				"b": {X: 2, Y: 2, Direction: cloudbowl.North, Score: -1, WasHit: true},
+			},
+		},
+		{
+			name: "throwing at each other",
+			players: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.West},
+			},
+			moves: map[string]cloudbowl.Move{"a": cloudbowl.Throw, "b": cloudbowl.Throw},
+			want: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East, WasHit: true},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.West, WasHit: true},
+			},
+		},
+		{
+			name: "being hit by two players",
+			players: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 1, Direction: cloudbowl.East},
+				"b": {X: 1, Y: 0, Direction: cloudbowl.South},
+				"c": {X: 1, Y: 1, Direction: cloudbowl.North},
+			},
+			moves: map[string]cloudbowl.Move{"a": cloudbowl.Throw, "b": cloudbowl.Throw},
+			want: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 1, Direction: cloudbowl.East, Score: 1},
+				"b": {X: 1, Y: 0, Direction: cloudbowl.South, Score: 1},
+				"c": {X: 1, Y: 1, Direction: cloudbowl.North, Score: -2, WasHit: true},
+			},
+		},
+		{
+			name:    "clearing the previous hit",
+			players: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.North, WasHit: true}},
+			moves:   map[string]cloudbowl.Move{},
+			want:    map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.North}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := cloudbowl.NewArenaUpdate(5, 4, test.players)
+			got, err := Next(state, test.moves)
+			if err != nil {
+				t.Fatalf("Next() returned an error: %v", err)
+			}
+			if want := cloudbowl.NewArenaUpdate(5, 4, test.want); !reflect.DeepEqual(got.Arena.State, want.Arena.State) {
+				t.Errorf("Next() = %+v, want %+v", got.Arena.State, want.Arena.State)
+			}
+			if !reflect.DeepEqual(state, cloudbowl.NewArenaUpdate(5, 4, test.players)) {
+				t.Errorf("Next() modified the state it was given")
+			}
+		})
+	}
+}
+
+func TestNextRejectsInvalidMoves(t *testing.T) {
+	state := cloudbowl.NewArenaUpdate(5, 4, map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.North}})
+	tests := []struct {
+		name  string
+		moves map[string]cloudbowl.Move
+	}{
+		{name: "unknown move", moves: map[string]cloudbowl.Move{"a": "X"}},
+		{name: "unknown player", moves: map[string]cloudbowl.Move{"b": cloudbowl.Forward}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Next(state, test.moves); err == nil {
+				t.Errorf("Next() returned no error")
+			}
+		})
+	}
+}
+
+func TestNewGame(t *testing.T) {
+	players := []string{"a", "b", "c", "d"}
+	state, err := NewGame(2, 2, players, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("NewGame() returned an error: %v", err)
+	}
+	squares := make(map[[2]int]bool)
+	for _, id := range players {
+		player, exists := state.Arena.State[id]
+		if !exists {
+			t.Fatalf("NewGame() left out %v", id)
+		}
+		if player.Id != id || !player.Direction.Valid() || player.X < 0 || player.X >= 2 || player.Y < 0 || player.Y >= 2 {
+			t.Errorf("NewGame() placed %v as %+v", id, player)
+		}
+		squares[[2]int{player.X, player.Y}] = true
+	}
+	if len(squares) != len(players) {
+		t.Errorf("NewGame() put more than one player on the same square: %+v", state.Arena.State)
+	}
+	if _, err := NewGame(1, 1, players, rand.New(rand.NewSource(1))); err == nil {
+		t.Errorf("NewGame() fit 4 players into a 1x1 arena")
+	}
+}
+
+func TestTargets(t *testing.T) {
+	state := cloudbowl.NewArenaUpdate(5, 4, map[string]cloudbowl.PlayerState{
+		"a": {X: 0, Y: 2, Direction: cloudbowl.East},
+		"b": {X: 1, Y: 2, Direction: cloudbowl.West},
+		"c": {X: 2, Y: 2, Direction: cloudbowl.North},
+	})
+	want := map[string]string{"a": "b", "b": "a"}
+	if got := Targets(state); !reflect.DeepEqual(got, want) {
+		t.Errorf("Targets() = %v, want %v", got, want)
+	}
+}
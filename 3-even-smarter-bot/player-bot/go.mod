@@ -31,3 +31,7 @@ require (
 	google.golang.org/protobuf v1.28.0 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
+
+require github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl v0.0.0-00010101000000-000000000000
+
+replace github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl => ../../cloudbowl
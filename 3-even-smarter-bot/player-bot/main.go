@@ -8,18 +8,21 @@ import (
 	"net/http"
 	"os"
 	"player-bot/board"
-	"player-bot/shared"
+	"player-bot/strategy"
+	"player-bot/tracker"
+	"strconv"
+	"time"
 
 	"cloud.google.com/go/compute/metadata"
 	"cloud.google.com/go/pubsub"
 
 	"github.com/gomodule/redigo/redis"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
 )
 
 var redisPool *redis.Pool
-
-var HIGH_SCORING_PERCENTILE = 0.5
-var MAX_THROW_DISTANCE = 3
+var botStrategy strategy.Strategy
 
 func main() {
 	redisHost := os.Getenv("REDIS_HOST")
@@ -32,38 +35,65 @@ func main() {
 		Dial:    func() (redis.Conn, error) { return redis.Dial("tcp", redisAddr) },
 	}
 
-	port := "8080"
-	if v := os.Getenv("PORT"); v != "" {
-		port = v
+	strategyName := os.Getenv("STRATEGY")
+	if strategyName == "" {
+		strategyName = "leaderboard-aware"
+	}
+	targetWeights := board.DEFAULT_TARGET_WEIGHTS
+	if value := os.Getenv("TARGET_WEIGHTS"); value != "" {
+		// e.g. {"ActionDistance": -1, "Score": 0.1}, any weight left out keeps its default
+		if err := json.Unmarshal([]byte(value), &targetWeights); err != nil {
+			log.Fatalf("invalid TARGET_WEIGHTS: %v", err)
+		}
+	}
+	targetSelectorName := os.Getenv("TARGET_SELECTOR")
+	if targetSelectorName == "" {
+		targetSelectorName = strategy.WEIGHTED_TARGETING
+	}
+	targetSelector, err := strategy.NewTargetSelector(targetSelectorName, targetWeights)
+	if err != nil {
+		log.Fatalf("invalid TARGET_SELECTOR: %v", err)
+	}
+	var movement strategy.Movement // nil leaves it up to the strategy
+	if value := os.Getenv("MOVEMENT_MODE"); value != "" {
+		var err error
+		if movement, err = strategy.NewMovement(value); err != nil {
+			log.Fatalf("invalid MOVEMENT_MODE: %v", err)
+		}
+	}
+	var budget time.Duration // zero leaves it up to the strategy
+	if value := os.Getenv("TIME_BUDGET"); value != "" {
+		var err error
+		if budget, err = time.ParseDuration(value); err != nil {
+			log.Fatalf("invalid TIME_BUDGET: %v", err)
+		}
+	}
+	// each instance of the bot only sees the updates sent to it, so share the players' histories in redis if asked to
+	var opponentTracker *tracker.Tracker // nil leaves it up to the strategy
+	if os.Getenv("TRACKER_STORE") == "redis" {
+		opponentTracker = tracker.New(tracker.NewRedisStore(redisPool), tracker.HISTORY_LENGTH)
+	}
+	botStrategy, err = strategy.New(strategyName, strategy.Options{Leaderboard: getLeaderboard, Grudges: getGrudges, Budget: budget, Tracker: opponentTracker, TargetSelector: targetSelector, Movement: movement, ObserveInBackground: true})
+	if err != nil {
+		log.Fatalf("failed to create strategy: %v", err)
 	}
-	http.HandleFunc("/", handler)
+	log.Printf("playing with the %v strategy", strategyName)
 
-	log.Printf("starting server on port :%v", port)
-	err := http.ListenAndServe(":"+port, nil)
+	http.HandleFunc("/", cloudbowl.BotHandlerContext(func(ctx context.Context, input cloudbowl.ArenaUpdate) cloudbowl.Move {
+		go postArenaUpdateEvent(input, time.Now()) // call this asynchonously
+		return play(ctx, input)
+	}))
+	err = cloudbowl.ListenAndServe(nil)
 	log.Fatalf("http listen error: %v", err)
 }
 
-func handler(w http.ResponseWriter, req *http.Request) {
-	if req.Method == http.MethodGet {
-		fmt.Fprint(w, "Let the battle begin!")
+// publishes the arena update for the event driven services, along with when it was received, since Pub/Sub may deliver
+// the updates out of order
+func postArenaUpdateEvent(input cloudbowl.ArenaUpdate, received time.Time) {
+	topicName := os.Getenv("ARENA_UPDATES_PUBSUB_TOPIC_NAME")
+	if topicName == "" { // e.g. when running locally against cmd/arena
 		return
 	}
-
-	var v ArenaUpdate
-	defer req.Body.Close()
-	d := json.NewDecoder(req.Body)
-	d.DisallowUnknownFields()
-	if err := d.Decode(&v); err != nil {
-		log.Printf("WARN: failed to decode ArenaUpdate in response body: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	go postArenaUpdateEvent(v) // call this asynchonously
-	resp := play(v)
-	fmt.Fprint(w, resp)
-}
-
-func postArenaUpdateEvent(input ArenaUpdate) {
 	ctx := context.Background()
 	metadataClient := metadata.NewClient(nil)
 	projectId, err := metadataClient.ProjectID()
@@ -75,12 +105,15 @@ func postArenaUpdateEvent(input ArenaUpdate) {
 		log.Fatalf("pubsub.NewClient: %v", err)
 	}
 	defer pubsubClient.Close()
-	topic := pubsubClient.Topic(os.Getenv("ARENA_UPDATES_PUBSUB_TOPIC_NAME"))
+	topic := pubsubClient.Topic(topicName)
 	message, err := json.Marshal(input)
 	if err != nil {
 		log.Fatalf("json.Marshal fatal error: %v", err)
 	}
-	publishResult := topic.Publish(ctx, &pubsub.Message{Data: message})
+	publishResult := topic.Publish(ctx, &pubsub.Message{
+		Data:       message,
+		Attributes: map[string]string{cloudbowl.RECEIVED_ATTRIBUTE: strconv.FormatInt(received.UnixMilli(), 10)},
+	})
 	id, err := publishResult.Get(ctx)
 	if err != nil {
 		log.Fatalf("topic.Publish fatal error: %v", err)
@@ -89,44 +122,12 @@ func postArenaUpdateEvent(input ArenaUpdate) {
 	topic.Stop()
 }
 
-func play(input ArenaUpdate) (response string) {
-	log.Printf("IN: %#v", input)
-	board := board.New(input.Arena.Dimensions[0], input.Arena.Dimensions[1], input.Arena.State)
-	myState := extractMyState(input)
-	log.Printf("i am at x:%v y:%v and I am facing %v", myState.X, myState.Y, myState.Direction)
-	// if we are the only player, just spin on the spot
-	if board.NumberOfPlayers == 1 {
-		log.Printf("there are no other players on the board")
-		return "R"
-	}
-	// check to see if there is a leaderboard available, otherwsie just look for closest player
-	leaderboard := getLeaderboard()
-	if leaderboard != nil {
-		// check if i am the leader and switch to only targeting high scoring players if so
-		if myState == leaderboard[0] {
-			log.Printf(("I am the leader, targeting high scoring players only"))
-			if board.IsThereAHighScoringOpponentInFrontOfMe(myState, MAX_THROW_DISTANCE, leaderboard, HIGH_SCORING_PERCENTILE) {
-				log.Printf("there is a highscoring opponent in front of me, so I am going to throw")
-				return "T"
-			} else {
-				log.Printf("there are no highscoring opponents to throw at")
-				return moveTowardsClosestHighScoringOpponent(myState, board, leaderboard)
-			}
-		} else {
-			log.Printf(("there is a leaderboard, but I am not the leader"))
-		}
-	}
-	// if we get to here either there was no leaderboard, or we are currently winning, so we switch to targeting all players
-	if board.IsThereAnOpponentInFrontOfMe(myState, MAX_THROW_DISTANCE) {
-		log.Printf("there is an opponent in front of me, so I am throwing")
-		return "T"
-	} else {
-		log.Printf("there are no opponents to throw at")
-		return moveTowardsClosestOpponent(myState, board)
-	}
+func play(ctx context.Context, input cloudbowl.ArenaUpdate) (response cloudbowl.Move) {
+	return strategy.PlayContext(ctx, botStrategy, input)
 }
 
-func getLeaderboard() []shared.PlayerState {
+// the leaderboard is maintained in redis by the leaderboard service
+func getLeaderboard(input cloudbowl.ArenaUpdate) []cloudbowl.PlayerState {
 	conn := redisPool.Get()
 	defer conn.Close()
 	leaderboardAsString, err := redis.String(conn.Do("GET", "leaderboard"))
@@ -134,7 +135,7 @@ func getLeaderboard() []shared.PlayerState {
 		log.Printf("error reading leaderboard from redis: %v", err)
 		return nil
 	}
-	var leaderboard []shared.PlayerState
+	var leaderboard []cloudbowl.PlayerState
 	err = json.Unmarshal([]byte(leaderboardAsString), &leaderboard)
 	if err != nil {
 		log.Printf("error unmarshalling leaderboard: %v", err)
@@ -144,139 +145,66 @@ func getLeaderboard() []shared.PlayerState {
 	return leaderboard
 }
 
-func extractMyState(input ArenaUpdate) shared.PlayerState {
-	myId := input.Links.Self.Href
-	state := input.Arena.State
-	return state[myId]
-}
-
-func moveTowardsClosestOpponent(myState shared.PlayerState, board board.Board) (response string) {
-	opponent := board.FindClosestOpponent(myState)
-	log.Printf("closest opponent is at x:%v y:%v", opponent.X, opponent.Y)
-	return determineNextMove(myState, opponent)
-}
-
-func moveTowardsClosestHighScoringOpponent(myState shared.PlayerState, board board.Board, leaderboard []shared.PlayerState) (response string) {
-	opponent := board.FindClosestHighScoringOpponent(myState, leaderboard, HIGH_SCORING_PERCENTILE)
-	log.Printf("closest high scoring opponent is at x:%v y:%v with a score of %v", opponent.X, opponent.Y, opponent.Score)
-	return determineNextMove(myState, opponent)
-}
-
-func determineNextMove(myState shared.PlayerState, opponentState shared.PlayerState) (result string) {
-	directionImFacing := myState.Direction
-	directionOfOpponent := determineDirectionOfOpponent(myState, opponentState)
-	switch directionImFacing {
-	case "N":
-		switch directionOfOpponent {
-		case "N":
-			fallthrough
-		case "NE":
-			fallthrough
-		case "NW":
-			result = "F"
-		case "E":
-			fallthrough
-		case "SE":
-			fallthrough
-		case "S":
-			result = "R"
-		case "SW":
-			fallthrough
-		default: // "W":
-			result = "L"
-		}
-	case "E":
-		switch directionOfOpponent {
-		case "N":
-			fallthrough
-		case "NW":
-			result = "L"
-		case "NE":
-			fallthrough
-		case "E":
-			fallthrough
-		case "SE":
-			result = "F"
-		case "S":
-			fallthrough
-		case "SW":
-			fallthrough
-		default: // "W":
-			result = "R"
-		}
-	case "S":
-		switch directionOfOpponent {
-		case "N":
-			fallthrough
-		case "W":
-			fallthrough
-		case "NW":
-			result = "R"
-		case "NE":
-			fallthrough
-		case "E":
-			result = "L"
-		case "SE":
-			fallthrough
-		case "S":
-			fallthrough
-		default: // "SW":
-			result = "F"
-		}
-	default: //W
-		switch directionOfOpponent {
-		case "N":
-			fallthrough
-		case "NE":
-			fallthrough
-		case "E":
-			result = "R"
-		case "SE":
-			fallthrough
-		case "S":
-			result = "L"
-		case "SW":
-			fallthrough
-		case "W":
-			fallthrough
-		default: // "NW":
-			result = "F"
-		}
+// everyone who has hit us, from the hit graph the leaderboard service keeps in redis, so the grudges survive restarts and
+// are the same for every instance of the bot
+func getGrudges(input cloudbowl.ArenaUpdate) map[string]board.Grudge {
+	conn := redisPool.Get()
+	defer conn.Close()
+	me := input.Links.Self.Href
+	// read everything in one go, so the hits and when they happened agree with each other
+	if err := conn.Send("MULTI"); err != nil {
+		log.Printf("error reading grudges from redis: %v", err)
+		return nil
 	}
-	log.Printf("direction I am facing is %v, direction of opponent is %v, therefore I am going to move %v", directionImFacing, directionOfOpponent, result)
-	return result
-}
-
-func determineDirectionOfOpponent(myState shared.PlayerState, opponentState shared.PlayerState) (result string) {
-	myXcoord := myState.X
-	myYcoord := myState.Y
-	opponentXcoord := opponentState.X
-	opponentYcoord := opponentState.Y
-	if myXcoord == opponentXcoord {
-		if myYcoord > opponentYcoord {
-			result = "N"
-		} else {
-			result = "S"
-		}
-	} else if myYcoord == opponentYcoord {
-		if myXcoord > opponentXcoord {
-			result = "W"
-		} else {
-			result = "E"
+	if err := conn.Send("HGETALL", "hits:"+me); err != nil {
+		log.Printf("error reading grudges from redis: %v", err)
+		return nil
+	}
+	if err := conn.Send("HGETALL", "last-hit:"+me); err != nil {
+		log.Printf("error reading grudges from redis: %v", err)
+		return nil
+	}
+	if err := conn.Send("GET", "arena-tick"); err != nil {
+		log.Printf("error reading grudges from redis: %v", err)
+		return nil
+	}
+	replies, err := redis.Values(conn.Do("EXEC"))
+	if err != nil {
+		log.Printf("error reading grudges from redis: %v", err)
+		return nil
+	}
+	hits, err := redis.StringMap(replies[0], nil)
+	if err != nil {
+		log.Printf("error reading hits from redis: %v", err)
+		return nil
+	}
+	lastHits, err := redis.IntMap(replies[1], nil)
+	if err != nil {
+		log.Printf("error reading last hits from redis: %v", err)
+		return nil
+	}
+	tick, err := redis.Int(replies[2], nil)
+	if err != nil && err != redis.ErrNil {
+		log.Printf("error reading arena tick from redis: %v", err)
+		return nil
+	}
+	tickKnown := err == nil
+	grudges := make(map[string]board.Grudge, len(hits))
+	for thrower, value := range hits {
+		count, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			log.Printf("error parsing hits by %v from redis: %v", thrower, err)
+			continue
 		}
-	} else if myYcoord > opponentYcoord {
-		if myXcoord > opponentXcoord {
-			result = "NW"
-		} else {
-			result = "NE"
+		ticksSince := board.LONG_AGO // without both ticks there is no telling how recent the hit was
+		if lastHit, hitAt := lastHits[thrower]; tickKnown && hitAt {
+			ticksSince = tick - lastHit
 		}
-	} else { // myYcoord < opponentYcoord
-		if myXcoord > opponentXcoord {
-			result = "SW"
-		} else {
-			result = "SE"
+		if ticksSince < 0 { // the tick has been reset since they hit us
+			ticksSince = 0
 		}
+		grudges[thrower] = board.Grudge{Hits: count, TicksSince: ticksSince}
 	}
-	log.Printf("i am at x:%v y:%v and opponent is at x:%v y:%v, so their direction from me is %v", myXcoord, myYcoord, opponentXcoord, opponentYcoord, result)
-	return result
+	log.Printf("grudges are: %v", grudges)
+	return grudges
 }
@@ -0,0 +1,43 @@
+package strategy
+
+import (
+	"log"
+	"player-bot/board"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+var POINTS_LOST_PER_LINE_OF_FIRE = 1.0 // expected cost of ending the tick in one opponent's line of fire
+var POINTS_GAINED_PER_THROW = 1.0      // expected gain from throwing at an opponent in front of us
+
+// when we were just hit, or an opponent could hit us with their next move, picks the move that leaves us in the
+// fewest lines of fire, unless throwing at someone in front of us is expected to score better. evading is false
+// when we aren't under fire, in which case the move should be ignored.
+func evade(myState cloudbowl.PlayerState, board board.Board, threats board.ThreatMap) (move cloudbowl.Move, evading bool) {
+	currentLines := threats.CountThreatsWithin(myState.X, myState.Y, 1)
+	if !myState.WasHit && currentLines == 0 {
+		return "", false
+	}
+	log.Printf("I am under fire (hit: %v, lines of fire: %v), looking for somewhere safer", myState.WasHit, currentLines)
+
+	bestLines, bestLater := -1, -1
+	for _, candidate := range cloudbowl.LegalMovements(board, myState) {
+		next, _ := board.Next(myState, candidate, cloudbowl.MAX_THROW_DISTANCE)
+		lines := threats.CountThreatsWithin(next.X, next.Y, 1)
+		later := threats.CountThreatsWithin(next.X, next.Y, threats.Horizon) // breaks ties between squares that are safe for now
+		if bestLines == -1 || lines < bestLines || (lines == bestLines && later < bestLater) {
+			move, bestLines, bestLater = candidate, lines, later
+		}
+	}
+
+	if board.IsThereAnOpponentInFrontOfMe(myState, cloudbowl.MAX_THROW_DISTANCE) {
+		throwScore := POINTS_GAINED_PER_THROW - POINTS_LOST_PER_LINE_OF_FIRE*float64(currentLines)
+		evadeScore := -POINTS_LOST_PER_LINE_OF_FIRE * float64(bestLines)
+		if throwScore > evadeScore {
+			log.Printf("throwing is worth %v but evading is only worth %v, so I am throwing", throwScore, evadeScore)
+			return cloudbowl.Throw, true
+		}
+	}
+	log.Printf("moving %v leaves me in %v lines of fire", move, bestLines)
+	return move, true
+}
@@ -0,0 +1,57 @@
+package strategy
+
+import (
+	"log"
+	"player-bot/board"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// what to do when there is nobody to go after: head for the least exposed square we can still throw from, and once
+// there face whichever way has the most room for a throw, so we are ready for whoever turns up. Every move it makes
+// changes something, so once settled it keeps turning rather than throw at nobody.
+func idle(myState cloudbowl.PlayerState, board board.Board) cloudbowl.Move {
+	safest, found := board.FindSafestSquare(myState, cloudbowl.MAX_THROW_DISTANCE)
+	if !found {
+		return cloudbowl.TurnRight
+	}
+	if plan, found := board.PlanPathTo(myState, safest); found && len(plan) > 0 {
+		log.Printf("heading for the safer square at x:%v y:%v, %v moves away", safest.X, safest.Y, len(plan))
+		return plan[0]
+	}
+
+	best, bestRoom := myState.Direction, roomInFront(myState, board)
+	for _, direction := range cloudbowl.Directions {
+		facing := myState
+		facing.Direction = direction
+		if room := roomInFront(facing, board); room > bestRoom {
+			best, bestRoom = direction, room
+		}
+	}
+	switch best {
+	case myState.Direction:
+		// throwing at nobody would waste the turn, so wait by turning to face whichever side has more room, which
+		// keeps us on the safe square
+		left, right := myState, myState
+		left.Direction = myState.Direction.RotateLeft()
+		right.Direction = myState.Direction.RotateRight()
+		log.Printf("I am in a safe square and facing the way with the most room, so I am turning while I wait")
+		if roomInFront(right, board) > roomInFront(left, board) {
+			return cloudbowl.TurnRight
+		}
+		return cloudbowl.TurnLeft
+	case myState.Direction.RotateLeft():
+		return cloudbowl.TurnLeft
+	default:
+		return cloudbowl.TurnRight
+	}
+}
+
+// counts the squares a throw in the direction the player is facing could travel through
+func roomInFront(myState cloudbowl.PlayerState, board board.Board) (result int) {
+	dx, dy := myState.Direction.Step()
+	for i := 1; i <= cloudbowl.MAX_THROW_DISTANCE && board.IsOnBoard(myState.X+dx*i, myState.Y+dy*i); i++ {
+		result++
+	}
+	return result
+}
@@ -0,0 +1,39 @@
+package strategy
+
+import (
+	"player-bot/board"
+	"testing"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+func TestIdleOnlyMakesLegalMoves(t *testing.T) {
+	for _, size := range [][2]int{{7, 5}, {4, 3}, {2, 2}} {
+		for x := 0; x < size[0]; x++ {
+			for y := 0; y < size[1]; y++ {
+				for _, direction := range cloudbowl.Directions {
+					me := cloudbowl.PlayerState{X: x, Y: y, Direction: direction}
+					input := sentTo("me", cloudbowl.NewArenaUpdate(size[0], size[1], map[string]cloudbowl.PlayerState{"me": me}))
+					move := idle(extractMyState(input), board.New(size[0], size[1], input.Arena.State))
+					if !isLegal(move, input) {
+						t.Errorf("idle() = %v at x:%v y:%v facing %v on a %vx%v board, which changes nothing", move, x, y, direction, size[0], size[1])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestIdleWaitsOnTheSafeSquare(t *testing.T) {
+	// a corner is as safe as it gets, and facing east has the most room on a wide board
+	me := cloudbowl.PlayerState{X: 0, Y: 0, Direction: cloudbowl.East}
+	input := sentTo("me", cloudbowl.NewArenaUpdate(7, 5, map[string]cloudbowl.PlayerState{"me": me}))
+	for tick := 0; tick < 4; tick++ {
+		move := idle(extractMyState(input), board.New(7, 5, input.Arena.State))
+		if move != cloudbowl.TurnLeft && move != cloudbowl.TurnRight {
+			t.Fatalf("idle() = %v on tick %v, want a turn that keeps us in the corner", move, tick)
+		}
+		next, _ := board.New(7, 5, input.Arena.State).Next(extractMyState(input), move, cloudbowl.MAX_THROW_DISTANCE)
+		input.Arena.State["me"] = next
+	}
+}
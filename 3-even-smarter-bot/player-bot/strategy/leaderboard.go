@@ -0,0 +1,64 @@
+package strategy
+
+import (
+	"log"
+	"player-bot/board"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+var MOVES_WORTH_WAITING_FOR_TARGET = 2 // throw at a blocker rather than spend this many moves lining up the target
+var SEEK_REVENGE = true                // once leading, go after the high scorers who have been hitting us before the closest one, see LEADERBOARD_TARGETING
+
+func init() {
+	RegisterTracking("leaderboard-aware", func(options Options) Strategy {
+		return LeaderboardAware(options.TargetSelector, options.Leaderboard, options.Grudges, options.MovePriors, options.Movement)
+	})
+}
+
+// the player-bot strategy: gets out of the way when under fire, otherwise leaves the choice of who to go after to the
+// selector. Throws if the target is in front of us, or if someone else is and the target is a way off, and heads
+// towards the target if not. A throw is only made if it is expected to score more than repositioning, going by how
+// likely each opponent is to make each move according to getMovePrior. getLeaderboard returns the current leaderboard
+// sorted from highest to lowest score, or nil if there isn't one, getGrudges returns everyone who has hit us, and
+// moveTowards decides how to head for the target.
+func LeaderboardAware(selector board.TargetSelector, getLeaderboard func(input cloudbowl.ArenaUpdate) []cloudbowl.PlayerState, getGrudges func(input cloudbowl.ArenaUpdate) map[string]board.Grudge, getMovePrior func(opponent cloudbowl.PlayerState) board.MovePrior, moveTowards Movement) Func {
+	return func(input cloudbowl.ArenaUpdate) (response cloudbowl.Move) {
+		log.Printf("IN: %#v", input)
+		board := board.New(input.Arena.Dimensions[0], input.Arena.Dimensions[1], input.Arena.State)
+		myState := extractMyState(input)
+		log.Printf("i am at x:%v y:%v and I am facing %v", myState.X, myState.Y, myState.Direction)
+		// if we are the only player, wait somewhere safe for someone to turn up
+		if board.NumberOfPlayers == 1 {
+			log.Printf("there are no other players on the board")
+			return idle(myState, board)
+		}
+		// work out where opponents could throw next, so we don't walk into their line of fire
+		threats := board.ThreatMap(myState, cloudbowl.MAX_THROW_DISTANCE, 2)
+		// if we are under fire, getting out of the way comes first
+		if move, evading := evade(myState, board, threats); evading {
+			return move
+		}
+		target, found := selector.Select(board, myState, getLeaderboard(input), threats, getGrudges(input))
+		if !found {
+			return idle(myState, board)
+		}
+		if opponent, inFront := board.FirstOpponentInFrontOfMe(myState, cloudbowl.MAX_THROW_DISTANCE); inFront && (opponent.Id == target.Id || isBlockerWorthHitting(myState, board, target)) {
+			log.Printf("the opponent at x:%v y:%v is in front of me, deciding whether to throw", opponent.X, opponent.Y)
+			move, throwing := throwOrReposition(myState, board, getMovePrior)
+			if throwing {
+				return move
+			}
+			return avoidLinesOfFire(myState, board, threats, move)
+		}
+		log.Printf("heading towards my target at x:%v y:%v", target.X, target.Y)
+		return avoidLinesOfFire(myState, board, threats, moveTowards(myState, target, board))
+	}
+}
+
+// a throw at whoever is in the way still earns us a point, so it's worth taking unless the target can be lined up almost
+// straight away
+func isBlockerWorthHitting(myState cloudbowl.PlayerState, board board.Board, target cloudbowl.PlayerState) bool {
+	ticks, reachable := board.ActionDistance(myState, target, cloudbowl.MAX_THROW_DISTANCE)
+	return !reachable || ticks >= MOVES_WORTH_WAITING_FOR_TARGET
+}
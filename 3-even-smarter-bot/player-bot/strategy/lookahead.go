@@ -0,0 +1,203 @@
+package strategy
+
+import (
+	"log"
+	"math"
+	"player-bot/board"
+	"player-bot/engine"
+	"time"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+var LOOKAHEAD_DEPTH = 2              // the most of our own moves to look ahead
+var LOOKAHEAD_OPPONENTS = 2          // how many of the nearest opponents are assumed to move, everyone else stands still
+var LOOKAHEAD_PARANOID = false       // assume the opponents always make the move that is worst for us, instead of weighing them by how likely they are
+var EXPOSURE_PENALTY = 1.0           // points a position is worth less for each opponent who could hit us from where they are
+var OPPONENT_THROW_PROBABILITY = 0.7 // how likely an opponent with someone in front of them is to throw
+
+func init() {
+	RegisterTracking("lookahead", func(options Options) Strategy {
+		return Lookahead(options.Budget, options.Iterations, options.TargetSelector, options.Leaderboard, options.Grudges, options.MovePriors, options.Movement)
+	})
+}
+
+// searches a few moves ahead using the engine, with our moves chosen to get the best result and the moves of the nearest
+// opponents weighed by how likely they are. Positions are rated by how many points we have gained and how many opponents
+// could hit us. The search goes one move deeper each time it completes, and when the budget runs out the best move from
+// the deepest completed search is played. If iterations is above 0 the search is cut short after looking at that many
+// positions instead, however long that takes. Only opponents close enough to reach us within the search are assumed to move,
+// and only moves that change something are searched, for us and for them. The leaderboard aware strategy, heading for
+// targets with moveTowards, breaks ties.
+func Lookahead(budget time.Duration, iterations int, selector board.TargetSelector, getLeaderboard func(input cloudbowl.ArenaUpdate) []cloudbowl.PlayerState, getGrudges func(input cloudbowl.ArenaUpdate) map[string]board.Grudge, getMovePrior func(opponent cloudbowl.PlayerState) board.MovePrior, moveTowards Movement) Func {
+	fallback := LeaderboardAware(selector, getLeaderboard, getGrudges, getMovePrior, moveTowards)
+	return func(input cloudbowl.ArenaUpdate) cloudbowl.Move {
+		log.Printf("IN: %#v", input)
+		deadline := time.Now().Add(budget)
+		board := board.New(input.Arena.Dimensions[0], input.Arena.Dimensions[1], input.Arena.State)
+		myState := extractMyState(input)
+		if board.NumberOfPlayers == 1 {
+			log.Printf("there are no other players on the board")
+			return idle(myState, board)
+		}
+
+		// the move the leaderboard aware strategy would make goes first, so it wins whenever the search can't tell the
+		// moves apart, e.g. when nobody is close enough to matter
+		preferred := fallback.Play(input)
+		var candidates []cloudbowl.Move
+		for _, move := range cloudbowl.LegalMoves(input, myState) {
+			if move == preferred {
+				candidates = append([]cloudbowl.Move{move}, candidates...)
+			} else {
+				candidates = append(candidates, move)
+			}
+		}
+		search := lookahead{me: myState.Id, rootScore: myState.Score, deadline: deadline, maxPositions: iterations}
+		reach := float64(cloudbowl.MAX_THROW_DISTANCE + 2*LOOKAHEAD_DEPTH) // both of us moving towards each other every tick
+		for _, opponent := range board.Index.WithinRadius(myState.X, myState.Y, reach, func(player cloudbowl.PlayerState) bool {
+			return player.Id != myState.Id
+		}) {
+			if len(search.opponents) == LOOKAHEAD_OPPONENTS {
+				break
+			}
+			search.opponents = append(search.opponents, opponent.Id)
+		}
+
+		result := candidates[0]
+		for depth := 1; depth <= LOOKAHEAD_DEPTH; depth++ {
+			move, value, complete := search.bestMove(input, depth, candidates)
+			if !complete {
+				if depth == 1 && move != "" { // better a partial search than none at all
+					result = move
+				}
+				log.Printf("ran out of time searching %v moves ahead after %v positions", depth, search.positions)
+				break
+			}
+			result = move
+			log.Printf("searching %v moves ahead, the best move is %v with a value of %.2f", depth, move, value)
+		}
+		log.Printf("searched %v positions, so I am going to move %v", search.positions, result)
+		return result
+	}
+}
+
+type lookahead struct {
+	me           string
+	opponents    []string // the opponents assumed to move, everyone else is assumed to stand still
+	rootScore    int
+	deadline     time.Time
+	maxPositions int // stop after looking at this many positions instead of at the deadline, unless 0
+	positions    int
+}
+
+// determines if the search has to stop where it is
+func (search *lookahead) isOutOfTime() bool {
+	if search.maxPositions > 0 {
+		return search.positions >= search.maxPositions
+	}
+	return time.Now().After(search.deadline)
+}
+
+// one combination of moves the opponents could make, and how likely it is
+type reply struct {
+	moves       map[string]cloudbowl.Move
+	probability float64
+}
+
+// finds the move worth the most when looking depth moves ahead. complete is false if time ran out first, in which case
+// the result is the best of the moves that were fully searched.
+func (search *lookahead) bestMove(state cloudbowl.ArenaUpdate, depth int, candidates []cloudbowl.Move) (best cloudbowl.Move, bestValue float64, complete bool) {
+	for _, move := range candidates {
+		value, searched := search.valueOf(state, move, depth)
+		if !searched {
+			return best, bestValue, false
+		}
+		if best == "" || value > bestValue+1e-9 { // rounding shouldn't be enough to prefer a later move
+			best = move
+			bestValue = value
+		}
+	}
+	return best, bestValue, true
+}
+
+// works out what our move is worth across the replies the opponents could make to it
+func (search *lookahead) valueOf(state cloudbowl.ArenaUpdate, move cloudbowl.Move, depth int) (result float64, complete bool) {
+	if LOOKAHEAD_PARANOID {
+		result = math.Inf(1)
+	}
+	for _, reply := range search.replies(state) {
+		if search.isOutOfTime() {
+			return result, false
+		}
+		moves := map[string]cloudbowl.Move{search.me: move}
+		for href, move := range reply.moves {
+			moves[href] = move
+		}
+		next, err := engine.Next(state, moves)
+		if err != nil {
+			log.Printf("failed to work out the next state: %v", err)
+			return result, false
+		}
+		search.positions++
+		var value float64
+		if depth == 1 {
+			value = search.evaluate(next)
+		} else if _, value, complete = search.bestMove(next, depth-1, cloudbowl.LegalMoves(next, next.Arena.State[search.me])); !complete {
+			return result, false
+		}
+		if LOOKAHEAD_PARANOID {
+			result = math.Min(result, value)
+		} else {
+			result += reply.probability * value
+		}
+	}
+	return result, true
+}
+
+// lists every combination of moves the opponents being searched could make, along with how likely each one is.
+// An opponent with someone in front of them will probably throw, otherwise they are equally likely to make any move
+// that changes something.
+func (search *lookahead) replies(state cloudbowl.ArenaUpdate) []reply {
+	targets := engine.Targets(state)
+	result := []reply{{moves: map[string]cloudbowl.Move{}, probability: 1}}
+	for _, opponent := range search.opponents {
+		movements := cloudbowl.LegalMovements(state, state.Arena.State[opponent])
+		movementProbability := 1.0
+		likelihoods := map[cloudbowl.Move]float64{}
+		if _, canHit := targets[opponent]; canHit {
+			likelihoods[cloudbowl.Throw] = OPPONENT_THROW_PROBABILITY
+			movementProbability = 1 - OPPONENT_THROW_PROBABILITY
+		}
+		for _, move := range movements {
+			likelihoods[move] = movementProbability / float64(len(movements))
+		}
+		var combined []reply
+		for _, partial := range result {
+			for _, move := range cloudbowl.Moves { // a fixed order keeps the search reproducible
+				likelihood, plausible := likelihoods[move]
+				if !plausible {
+					continue
+				}
+				moves := map[string]cloudbowl.Move{opponent: move}
+				for href, move := range partial.moves {
+					moves[href] = move
+				}
+				combined = append(combined, reply{moves: moves, probability: partial.probability * likelihood})
+			}
+		}
+		result = combined
+	}
+	return result
+}
+
+// rates a position by the points we have gained since the search started, less a penalty for every opponent who could
+// hit us with their next move
+func (search *lookahead) evaluate(state cloudbowl.ArenaUpdate) float64 {
+	exposure := 0
+	for _, target := range engine.Targets(state) {
+		if target == search.me {
+			exposure++
+		}
+	}
+	return float64(state.Arena.State[search.me].Score-search.rootScore) - EXPOSURE_PENALTY*float64(exposure)
+}
@@ -0,0 +1,98 @@
+package strategy
+
+import (
+	"math"
+	"player-bot/board"
+	"testing"
+	"time"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+func newLookahead(iterations int, moveTowards Movement) Func {
+	noGrudges := func(input cloudbowl.ArenaUpdate) map[string]board.Grudge { return nil }
+	defaultPrior := func(opponent cloudbowl.PlayerState) board.MovePrior { return board.DEFAULT_MOVE_PRIOR }
+	selector := board.NewWeightedTargetSelector(board.DEFAULT_TARGET_WEIGHTS, cloudbowl.MAX_THROW_DISTANCE)
+	return Lookahead(time.Hour, iterations, selector, LeaderboardFromArena, noGrudges, defaultPrior, moveTowards)
+}
+
+func TestLookaheadOnlyMakesLegalMoves(t *testing.T) {
+	lookahead := newLookahead(500, determineNextMove)
+	for i, input := range randomArenas(t, 150, 1) {
+		if move := lookahead(input); !isLegal(move, input) {
+			t.Errorf("Lookahead() = %v in arena %v, %+v, which changes nothing", move, i, input.Arena.State)
+		}
+	}
+}
+
+func TestLookaheadHeadsForTargetsWithTheMovement(t *testing.T) {
+	// nobody is close enough to matter, so the search can't tell the moves apart and the fallback decides
+	input := sentTo("me", cloudbowl.NewArenaUpdate(20, 15, map[string]cloudbowl.PlayerState{
+		"me": {X: 0, Y: 0, Direction: cloudbowl.East},
+		"a":  {X: 19, Y: 14, Direction: cloudbowl.North},
+	}))
+	called := false
+	moveTowards := func(myState cloudbowl.PlayerState, opponentState cloudbowl.PlayerState, board board.Board) cloudbowl.Move {
+		called = true
+		return cloudbowl.TurnRight
+	}
+	if move := newLookahead(500, moveTowards)(input); !called || move != cloudbowl.TurnRight {
+		t.Errorf("Lookahead() = %v, called the movement: %v, want the movement's move", move, called)
+	}
+}
+
+func TestLookaheadReplies(t *testing.T) {
+	tests := []struct {
+		name     string
+		opponent cloudbowl.PlayerState
+		want     map[cloudbowl.Move]float64
+	}{
+		{
+			name:     "free to move",
+			opponent: cloudbowl.PlayerState{X: 2, Y: 2, Direction: cloudbowl.North},
+			want:     map[cloudbowl.Move]float64{cloudbowl.Forward: 1.0 / 3, cloudbowl.TurnLeft: 1.0 / 3, cloudbowl.TurnRight: 1.0 / 3},
+		},
+		{
+			name:     "facing a wall",
+			opponent: cloudbowl.PlayerState{X: 2, Y: 0, Direction: cloudbowl.North},
+			want:     map[cloudbowl.Move]float64{cloudbowl.TurnLeft: 0.5, cloudbowl.TurnRight: 0.5},
+		},
+		{
+			name:     "facing us",
+			opponent: cloudbowl.PlayerState{X: 2, Y: 4, Direction: cloudbowl.West},
+			want: map[cloudbowl.Move]float64{
+				cloudbowl.Throw: OPPONENT_THROW_PROBABILITY, cloudbowl.TurnLeft: (1 - OPPONENT_THROW_PROBABILITY) / 3,
+				cloudbowl.TurnRight: (1 - OPPONENT_THROW_PROBABILITY) / 3, cloudbowl.Forward: (1 - OPPONENT_THROW_PROBABILITY) / 3,
+			},
+		},
+		{
+			name:     "facing us from next door",
+			opponent: cloudbowl.PlayerState{X: 1, Y: 4, Direction: cloudbowl.West},
+			want: map[cloudbowl.Move]float64{
+				cloudbowl.Throw: OPPONENT_THROW_PROBABILITY, cloudbowl.TurnLeft: (1 - OPPONENT_THROW_PROBABILITY) / 2,
+				cloudbowl.TurnRight: (1 - OPPONENT_THROW_PROBABILITY) / 2,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := cloudbowl.NewArenaUpdate(7, 5, map[string]cloudbowl.PlayerState{
+				"me": {X: 0, Y: 4, Direction: cloudbowl.North},
+				"a":  test.opponent,
+			})
+			search := lookahead{me: "me", opponents: []string{"a"}}
+			got := make(map[cloudbowl.Move]float64)
+			for _, reply := range search.replies(state) {
+				got[reply.moves["a"]] += reply.probability
+			}
+			if len(got) != len(test.want) {
+				t.Errorf("replies() = %v, want %v", got, test.want)
+			}
+			for move, want := range test.want {
+				if math.Abs(got[move]-want) > 1e-9 {
+					t.Errorf("replies()[%v] = %v, want %v", move, got[move], want)
+				}
+			}
+		})
+	}
+}
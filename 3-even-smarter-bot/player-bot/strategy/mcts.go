@@ -0,0 +1,208 @@
+package strategy
+
+import (
+	"context"
+	"log"
+	"math"
+	"math/rand"
+	"player-bot/board"
+	"player-bot/engine"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+var MCTS_HORIZON = 8             // how many ticks each playout looks ahead, through the tree and then the rollout
+var MCTS_EXPLORATION = 1.4       // how much UCT favours moves that have been tried less over moves that have done well
+var MCTS_DISCOUNT = 0.9          // how much less points are worth for each tick further into the future they are gained
+var MCTS_WORKERS = 1             // how many goroutines run playouts, each growing its own tree
+var MCTS_THROW_PROBABILITY = 0.9 // how likely a player with someone in front of them is to throw during a rollout
+
+func init() {
+	Register("mcts", func(options Options) Strategy { return NewMonteCarlo(options.Rng, options.Budget, options.Iterations) })
+}
+
+// plays out many short random futures from the current arena update, using UCT to spend more of them on the moves that
+// look the most promising, and plays the move that was tried the most. Opponents make heuristic moves: they throw if
+// someone is in front of them and otherwise move at random. Our moves are tracked in the tree, but not the opponents',
+// so the same tree node covers every way the opponents could have moved. Only moves that change something are tried,
+// for us and for them, so which of a node's moves can be chosen depends on how the opponents moved to get there.
+type MonteCarlo struct {
+	rng        *rand.Rand
+	budget     time.Duration
+	iterations int // when above 0, the number of playouts to make instead of searching for the budget
+}
+
+func NewMonteCarlo(rng *rand.Rand, budget time.Duration, iterations int) *MonteCarlo {
+	return &MonteCarlo{rng: rng, budget: budget, iterations: iterations}
+}
+
+func (strategy *MonteCarlo) Play(input cloudbowl.ArenaUpdate) cloudbowl.Move {
+	return strategy.PlayContext(context.Background(), input)
+}
+
+// searches until the budget is used up, or the iterations have been played out if there is a limit on them, or the
+// context is done, whichever comes first
+func (strategy *MonteCarlo) PlayContext(ctx context.Context, input cloudbowl.ArenaUpdate) cloudbowl.Move {
+	log.Printf("IN: %#v", input)
+	myState := extractMyState(input)
+	if len(input.Arena.State) == 1 {
+		log.Printf("there are no other players on the board")
+		return idle(myState, board.New(input.Arena.Dimensions[0], input.Arena.Dimensions[1], input.Arena.State))
+	}
+	playoutsPerWorker := 0 // no limit
+	if strategy.iterations > 0 {
+		playoutsPerWorker = (strategy.iterations + MCTS_WORKERS - 1) / MCTS_WORKERS
+	} else {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, strategy.budget)
+		defer cancel()
+	}
+
+	hrefs := make([]string, 0, len(input.Arena.State))
+	for href := range input.Arena.State {
+		hrefs = append(hrefs, href)
+	}
+	sort.Strings(hrefs) // a fixed order keeps the rng draws reproducible
+
+	// each worker draws from its own rng, seeded from ours, so they never wait on each other
+	roots := make([]*mctsNode, MCTS_WORKERS)
+	var wg sync.WaitGroup
+	for w := range roots {
+		roots[w] = newMctsNode()
+		search := mcts{me: myState.Id, root: input, hrefs: hrefs, rng: rand.New(rand.NewSource(strategy.rng.Int63()))}
+		wg.Add(1)
+		go func(root *mctsNode) {
+			defer wg.Done()
+			for played := 0; ctx.Err() == nil && (playoutsPerWorker == 0 || played < playoutsPerWorker); played++ {
+				search.playout(root)
+			}
+		}(roots[w])
+	}
+	wg.Wait()
+
+	// add up what every worker found out about each of our moves
+	playouts := 0
+	visits := make(map[cloudbowl.Move]int)
+	totals := make(map[cloudbowl.Move]float64)
+	for _, root := range roots {
+		playouts += root.visits
+		for move, child := range root.children {
+			visits[move] += child.visits
+			totals[move] += child.total
+		}
+	}
+	legal := cloudbowl.LegalMoves(input, myState)
+	result := legal[0]
+	for _, move := range legal {
+		if visits[move] > visits[result] || (visits[move] == visits[result] && totals[move] > totals[result]) {
+			result = move
+		}
+	}
+	for _, move := range cloudbowl.Moves {
+		if visits[move] > 0 {
+			log.Printf("%v was tried %v times with an average of %.2f points", move, visits[move], totals[move]/float64(visits[move]))
+		}
+	}
+	log.Printf("played out %v futures, so I am going to move %v", playouts, result)
+	return result
+}
+
+// the statistics for one sequence of our moves
+type mctsNode struct {
+	visits   int
+	total    float64 // the sum of the points won in every playout through this node
+	children map[cloudbowl.Move]*mctsNode
+}
+
+func newMctsNode() *mctsNode {
+	return &mctsNode{children: make(map[cloudbowl.Move]*mctsNode)}
+}
+
+type mcts struct {
+	me    string
+	root  cloudbowl.ArenaUpdate
+	hrefs []string // every player, in the order their moves are picked
+	rng   *rand.Rand
+}
+
+// plays one future out to the horizon: down the tree choosing our moves with UCT until reaching a move that hasn't been
+// tried yet, then at random for the rest of the way, crediting the points we won to every node along the way
+func (search mcts) playout(root *mctsNode) {
+	state := search.root
+	path := []*mctsNode{root}
+	node := root
+	reward := 0.0
+	for tick := 0; tick < MCTS_HORIZON; tick++ {
+		targets := engine.Targets(state)
+		var move cloudbowl.Move
+		if node != nil {
+			move = search.selectMove(node, cloudbowl.LegalMoves(state, state.Arena.State[search.me]))
+			child, tried := node.children[move]
+			if !tried {
+				child = newMctsNode()
+				node.children[move] = child
+			}
+			path = append(path, child)
+			if tried {
+				node = child
+			} else {
+				node = nil // the rest of the playout is a rollout
+			}
+		} else {
+			move = search.heuristicMove(state, targets, search.me)
+		}
+		next, err := engine.Next(state, search.opponentMoves(state, targets, move))
+		if err != nil {
+			log.Printf("failed to work out the next state: %v", err)
+			return
+		}
+		reward += math.Pow(MCTS_DISCOUNT, float64(tick)) * float64(next.Arena.State[search.me].Score-state.Arena.State[search.me].Score)
+		state = next
+	}
+	for _, node := range path {
+		node.visits++
+		node.total += reward
+	}
+}
+
+// picks the first of the legal moves that hasn't been tried from the node, or the one with the best upper confidence bound
+func (search mcts) selectMove(node *mctsNode, legal []cloudbowl.Move) (result cloudbowl.Move) {
+	best := math.Inf(-1)
+	for _, move := range legal {
+		child, tried := node.children[move]
+		if !tried {
+			return move
+		}
+		bound := child.total/float64(child.visits) + MCTS_EXPLORATION*math.Sqrt(math.Log(float64(node.visits))/float64(child.visits))
+		if bound > best {
+			best = bound
+			result = move
+		}
+	}
+	return result
+}
+
+// picks every other player's move to go with ours
+func (search mcts) opponentMoves(state cloudbowl.ArenaUpdate, targets map[string]string, myMove cloudbowl.Move) map[string]cloudbowl.Move {
+	moves := make(map[string]cloudbowl.Move, len(search.hrefs))
+	for _, href := range search.hrefs {
+		if href == search.me {
+			moves[href] = myMove
+		} else {
+			moves[href] = search.heuristicMove(state, targets, href)
+		}
+	}
+	return moves
+}
+
+// throws most of the time if there is someone to hit, otherwise makes one of the moves that changes something at random
+func (search mcts) heuristicMove(state cloudbowl.ArenaUpdate, targets map[string]string, href string) cloudbowl.Move {
+	if _, canHit := targets[href]; canHit && search.rng.Float64() < MCTS_THROW_PROBABILITY {
+		return cloudbowl.Throw
+	}
+	movements := cloudbowl.LegalMovements(state, state.Arena.State[href])
+	return movements[search.rng.Intn(len(movements))]
+}
@@ -0,0 +1,26 @@
+package strategy
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestMonteCarloOnlyMakesLegalMoves(t *testing.T) {
+	mcts := NewMonteCarlo(rand.New(rand.NewSource(1)), time.Hour, 200)
+	for i, input := range randomArenas(t, 150, 1) {
+		if move := mcts.Play(input); !isLegal(move, input) {
+			t.Errorf("Play() = %v in arena %v, %+v, which changes nothing", move, i, input.Arena.State)
+		}
+	}
+}
+
+func TestMonteCarloIsReproducibleWithIterations(t *testing.T) {
+	for i, input := range randomArenas(t, 20, 2) {
+		first := NewMonteCarlo(rand.New(rand.NewSource(int64(i))), time.Hour, 200).Play(input)
+		second := NewMonteCarlo(rand.New(rand.NewSource(int64(i))), time.Hour, 200).Play(input)
+		if first != second {
+			t.Errorf("Play() = %v and then %v in arena %v with the same seed", first, second, i)
+		}
+	}
+}
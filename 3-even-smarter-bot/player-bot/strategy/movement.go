@@ -0,0 +1,101 @@
+package strategy
+
+import (
+	"fmt"
+	"log"
+	"player-bot/board"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// decides the next move to take towards lining up a throw at the opponent
+type Movement func(myState cloudbowl.PlayerState, opponentState cloudbowl.PlayerState, board board.Board) cloudbowl.Move
+
+const BEARING_MOVEMENT = "bearing"
+const ALIGNMENT_MOVEMENT = "alignment"
+
+var movements = map[string]Movement{
+	BEARING_MOVEMENT:   determineNextMove,
+	ALIGNMENT_MOVEMENT: alignWithOpponent,
+}
+
+// looks up a movement mode by name, one of BEARING_MOVEMENT or ALIGNMENT_MOVEMENT
+func NewMovement(name string) (Movement, error) {
+	movement, exists := movements[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown movement mode %q, expected %q or %q", name, BEARING_MOVEMENT, ALIGNMENT_MOVEMENT)
+	}
+	return movement, nil
+}
+
+func moveTowardsClosestOpponent(myState cloudbowl.PlayerState, board board.Board, moveTowards Movement) (response cloudbowl.Move) {
+	opponent := board.FindClosestOpponent(myState, cloudbowl.MAX_THROW_DISTANCE)
+	log.Printf("closest opponent is at x:%v y:%v", opponent.X, opponent.Y)
+	return moveTowards(myState, opponent, board)
+}
+
+// heads for a square in the same row or column as the opponent that we can throw at them from, but they can't throw at
+// us from without turning, and throws once we are in one. Heading in their general direction with determineNextMove
+// can walk us diagonally past every such square, so that is only done when there is no square like that to be had.
+func alignWithOpponent(myState cloudbowl.PlayerState, opponentState cloudbowl.PlayerState, board board.Board) cloudbowl.Move {
+	plan, found := board.PlanAlignment(myState, opponentState, cloudbowl.MAX_THROW_DISTANCE)
+	if !found {
+		log.Printf("there is nowhere safe to line up on the opponent at x:%v y:%v, so heading towards them", opponentState.X, opponentState.Y)
+		return determineNextMove(myState, opponentState, board)
+	}
+	if len(plan) == 0 {
+		log.Printf("I am already lined up on the opponent at x:%v y:%v, so I am throwing", opponentState.X, opponentState.Y)
+		return cloudbowl.Throw
+	}
+	log.Printf("lining up on the opponent at x:%v y:%v is %v moves away: %v", opponentState.X, opponentState.Y, len(plan), plan)
+	return plan[0]
+}
+
+func determineNextMove(myState cloudbowl.PlayerState, opponentState cloudbowl.PlayerState, board board.Board) (result cloudbowl.Move) {
+	directionImFacing := myState.Direction
+	directionOfOpponent := determineDirectionOfOpponent(myState, opponentState)
+	// head forward if the opponent is ahead of us or diagonally ahead, otherwise turn towards them,
+	// preferring to turn right when they are directly behind us
+	switch relativeBearing := directionImFacing.Bearing().EighthsClockwiseTo(directionOfOpponent); relativeBearing {
+	case 7, 0, 1:
+		result = cloudbowl.Forward
+		if cloudbowl.IsForwardBlocked(board, myState) { // walking into something would waste the turn, so turn towards them instead
+			if relativeBearing == 7 {
+				result = cloudbowl.TurnLeft
+			} else {
+				result = cloudbowl.TurnRight
+			}
+		}
+	case 2, 3, 4:
+		result = cloudbowl.TurnRight
+	default: // 5, 6
+		result = cloudbowl.TurnLeft
+	}
+	log.Printf("direction I am facing is %v, direction of opponent is %v, therefore I am going to move %v", directionImFacing, directionOfOpponent, result)
+	return result
+}
+
+func determineDirectionOfOpponent(myState cloudbowl.PlayerState, opponentState cloudbowl.PlayerState) (result cloudbowl.Bearing) {
+	result = cloudbowl.BearingBetween(myState.X, myState.Y, opponentState.X, opponentState.Y)
+	log.Printf("i am at x:%v y:%v and opponent is at x:%v y:%v, so their direction from me is %v", myState.X, myState.Y, opponentState.X, opponentState.Y, result)
+	return result
+}
+
+// swaps a move that would leave us standing in an opponent's line of fire for one that doesn't, if there is one
+func avoidLinesOfFire(myState cloudbowl.PlayerState, board board.Board, threats board.ThreatMap, move cloudbowl.Move) cloudbowl.Move {
+	next, _ := board.Next(myState, move, cloudbowl.MAX_THROW_DISTANCE)
+	if !threats.IsInLineOfFire(next.X, next.Y) {
+		return move
+	}
+	for _, alternative := range cloudbowl.LegalMovements(board, myState) {
+		if alternative == move {
+			continue
+		}
+		if next, _ := board.Next(myState, alternative, cloudbowl.MAX_THROW_DISTANCE); !threats.IsInLineOfFire(next.X, next.Y) {
+			log.Printf("moving %v would leave me in an opponent's line of fire, so I am going to move %v instead", move, alternative)
+			return alternative
+		}
+	}
+	log.Printf("every move leaves me in an opponent's line of fire, so I am sticking with %v", move)
+	return move
+}
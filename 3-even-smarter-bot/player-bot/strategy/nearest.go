@@ -0,0 +1,26 @@
+package strategy
+
+import (
+	"log"
+	"player-bot/board"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+func init() {
+	Register("nearest-target", func(options Options) Strategy { return NearestTarget() })
+}
+
+// the 2-smarter-bot strategy: throws at anyone in front of us, otherwise heads towards the closest player
+func NearestTarget() Func {
+	return func(input cloudbowl.ArenaUpdate) cloudbowl.Move {
+		log.Printf("IN: %#v", input)
+		board := board.New(input.Arena.Dimensions[0], input.Arena.Dimensions[1], input.Arena.State)
+		myState := extractMyState(input)
+		if board.IsThereAnOpponentInFrontOfMe(myState, cloudbowl.MAX_THROW_DISTANCE) {
+			log.Println("throwing because someone is in front of me")
+			return cloudbowl.Throw
+		}
+		return moveTowardsClosestOpponent(myState, board, determineNextMove)
+	}
+}
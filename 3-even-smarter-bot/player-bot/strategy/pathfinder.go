@@ -0,0 +1,36 @@
+package strategy
+
+import (
+	"log"
+	"player-bot/board"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+func init() {
+	Register("pathfinder", func(options Options) Strategy { return Pathfinder() })
+}
+
+// throws at anyone in front of us, otherwise follows the shortest path, turns included, to a square we can throw from
+func Pathfinder() Func {
+	return func(input cloudbowl.ArenaUpdate) cloudbowl.Move {
+		log.Printf("IN: %#v", input)
+		board := board.New(input.Arena.Dimensions[0], input.Arena.Dimensions[1], input.Arena.State)
+		myState := extractMyState(input)
+		if board.NumberOfPlayers == 1 {
+			log.Printf("there are no other players on the board")
+			return idle(myState, board)
+		}
+		plan, found := board.PlanPathToFiringPosition(myState, cloudbowl.MAX_THROW_DISTANCE)
+		if !found {
+			log.Printf("there is no path to a firing position, so heading towards the closest opponent")
+			return moveTowardsClosestOpponent(myState, board, determineNextMove)
+		}
+		if len(plan) == 0 {
+			log.Printf("there is an opponent in front of me, so I am throwing")
+			return cloudbowl.Throw
+		}
+		log.Printf("the closest firing position is %v moves away: %v", len(plan), plan)
+		return plan[0]
+	}
+}
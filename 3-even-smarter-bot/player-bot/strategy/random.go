@@ -0,0 +1,21 @@
+package strategy
+
+import (
+	"log"
+	"math/rand"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+func init() {
+	Register("random", func(options Options) Strategy { return Random(options.Rng) })
+}
+
+// the 1-dumb-bot strategy: picks a move at random, though only from the moves that would actually change something
+func Random(rng *rand.Rand) Func {
+	return func(input cloudbowl.ArenaUpdate) cloudbowl.Move {
+		log.Printf("IN: %#v", input)
+		commands := cloudbowl.LegalMoves(input, extractMyState(input))
+		return commands[rng.Intn(len(commands))]
+	}
+}
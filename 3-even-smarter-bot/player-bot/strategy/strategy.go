@@ -0,0 +1,223 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"player-bot/board"
+	"player-bot/tracker"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+var HIGH_SCORING_PERCENTILE = 0.5
+var DEFAULT_BUDGET = 200 * time.Millisecond // the arena gives up on a bot that takes too long, so leave plenty of headroom
+
+// decides the next move for the player identified by input.Links.Self.Href
+type Strategy interface {
+	Play(input cloudbowl.ArenaUpdate) cloudbowl.Move
+}
+
+// allows an ordinary function to be used as a Strategy
+type Func func(input cloudbowl.ArenaUpdate) cloudbowl.Move
+
+func (f Func) Play(input cloudbowl.ArenaUpdate) cloudbowl.Move {
+	return f(input)
+}
+
+// a strategy that can cut its search short when the request it is answering is cancelled, e.g. because the arena
+// stopped waiting for the move
+type ContextStrategy interface {
+	Strategy
+	PlayContext(ctx context.Context, input cloudbowl.ArenaUpdate) cloudbowl.Move
+}
+
+// asks the strategy for its move, passing the context along if the strategy can make use of it
+func PlayContext(ctx context.Context, strategy Strategy, input cloudbowl.ArenaUpdate) cloudbowl.Move {
+	if s, ok := strategy.(ContextStrategy); ok {
+		return s.PlayContext(ctx, input)
+	}
+	return strategy.Play(input)
+}
+
+// everything a strategy may depend on, so the same strategy can be played by the deployed bot and in simulations
+type Options struct {
+	Rng *rand.Rand
+	// returns the current leaderboard sorted from highest to lowest score, or nil if there isn't one
+	Leaderboard func(input cloudbowl.ArenaUpdate) []cloudbowl.PlayerState
+	// how long a strategy that searches may spend deciding each move
+	Budget time.Duration
+	// when above 0, strategies that search stop after this many steps instead of when Budget runs out, so that a seeded
+	// simulation plays the same moves however fast the machine is. What a step is depends on the search, e.g. a position
+	// for lookahead and a playout for mcts.
+	Iterations int
+	// remembers how every player has been behaving, it observes each update the strategy is asked to play
+	Tracker *tracker.Tracker
+	// returns everyone who has hit us, indexed by their Id, going by what the tracker has seen if not provided
+	Grudges func(input cloudbowl.ArenaUpdate) map[string]board.Grudge
+	// how strategies pick which opponent to go after, see NewTargetSelector
+	TargetSelector board.TargetSelector
+	// returns how likely an opponent is to make each move next
+	MovePriors func(opponent cloudbowl.PlayerState) board.MovePrior
+	// how strategies head for the opponent they are targeting, see NewMovement
+	Movement Movement
+	// observe updates on another goroutine rather than before each move, which the deployed bot wants so that it never
+	// waits on the tracker's store, and simulations don't so that they stay reproducible
+	ObserveInBackground bool
+}
+
+// creates a new instance of a strategy
+type Factory func(options Options) Strategy
+
+type registration struct {
+	factory  Factory
+	tracking bool // whether the strategy reads the tracker, so the tracker needs to observe every update it plays
+}
+
+var registry = map[string]registration{}
+
+// makes a strategy available by name, normally called from an init function next to the strategy
+func Register(name string, factory Factory) {
+	register(name, registration{factory: factory})
+}
+
+// makes a strategy that reads the tracker available by name, i.e. one that uses Options.Tracker, Options.Grudges or
+// Options.MovePriors
+func RegisterTracking(name string, factory Factory) {
+	register(name, registration{factory: factory, tracking: true})
+}
+
+func register(name string, registration registration) {
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("strategy %q is already registered", name))
+	}
+	registry[name] = registration
+}
+
+// creates the strategy registered with the provided name, filling in any options that were not provided
+func New(name string, options Options) (Strategy, error) {
+	registration, exists := registry[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown strategy %q, expected one of %v", name, Names())
+	}
+	if options.Rng == nil {
+		options.Rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	// Cloud Run asks for several moves at once, and rand.Rand isn't safe to share between goroutines
+	options.Rng = rand.New(&lockedSource{rng: options.Rng})
+	if options.Leaderboard == nil {
+		options.Leaderboard = LeaderboardFromArena
+	}
+	if options.Budget <= 0 {
+		options.Budget = DEFAULT_BUDGET
+	}
+	if options.TargetSelector == nil {
+		options.TargetSelector = board.NewWeightedTargetSelector(board.DEFAULT_TARGET_WEIGHTS, cloudbowl.MAX_THROW_DISTANCE)
+	}
+	if options.Movement == nil {
+		options.Movement = determineNextMove
+	}
+	if options.Tracker == nil {
+		options.Tracker = tracker.New(tracker.NewMemoryStore(), tracker.HISTORY_LENGTH)
+	}
+	if options.Grudges == nil {
+		options.Grudges = func(input cloudbowl.ArenaUpdate) map[string]board.Grudge {
+			return options.Tracker.Grudges(input.Links.Self.Href)
+		}
+	}
+	if options.MovePriors == nil {
+		options.MovePriors = func(opponent cloudbowl.PlayerState) board.MovePrior {
+			return options.Tracker.MovePrior(opponent.Id)
+		}
+	}
+	strategy := registration.factory(options)
+	if !registration.tracking {
+		return strategy, nil
+	}
+	return tracked{strategy: strategy, tracker: options.Tracker, background: options.ObserveInBackground}, nil
+}
+
+// passes every draw straight through to the rng it wraps, one goroutine at a time, so a seeded rng still gives the
+// same moves
+type lockedSource struct {
+	mutex sync.Mutex
+	rng   *rand.Rand
+}
+
+func (source *lockedSource) Int63() int64 {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	return source.rng.Int63()
+}
+
+func (source *lockedSource) Uint64() uint64 {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	return source.rng.Uint64()
+}
+
+func (source *lockedSource) Seed(seed int64) {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	source.rng.Seed(seed)
+}
+
+// keeps the tracker up to date with every update the strategy decides what to do with
+type tracked struct {
+	strategy   Strategy
+	tracker    *tracker.Tracker
+	background bool
+}
+
+func (t tracked) observe(input cloudbowl.ArenaUpdate) {
+	if t.background {
+		t.tracker.ObserveInBackground(input)
+	} else {
+		t.tracker.Observe(input)
+	}
+}
+
+func (t tracked) Play(input cloudbowl.ArenaUpdate) cloudbowl.Move {
+	t.observe(input)
+	return t.strategy.Play(input)
+}
+
+func (t tracked) PlayContext(ctx context.Context, input cloudbowl.ArenaUpdate) cloudbowl.Move {
+	t.observe(input)
+	return PlayContext(ctx, t.strategy, input)
+}
+
+// lists the names of all registered strategies in alphabetical order
+func Names() (result []string) {
+	for name := range registry {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func extractMyState(input cloudbowl.ArenaUpdate) cloudbowl.PlayerState {
+	myId := input.Links.Self.Href
+	state := input.Arena.State
+	myState := state[myId]
+	myState.Id = myId
+	return myState
+}
+
+// ranks the players in the provided arena update from highest to lowest score, the same way the leaderboard service does
+func LeaderboardFromArena(input cloudbowl.ArenaUpdate) (leaderboard []cloudbowl.PlayerState) {
+	for k, v := range input.Arena.State {
+		v.Id = k
+		leaderboard = append(leaderboard, v)
+	}
+	sort.Slice(leaderboard, func(i, j int) bool {
+		if leaderboard[i].Score == leaderboard[j].Score {
+			return leaderboard[i].Id < leaderboard[j].Id // keeps simulations reproducible
+		}
+		return leaderboard[i].Score > leaderboard[j].Score
+	})
+	return leaderboard
+}
@@ -0,0 +1,46 @@
+package strategy
+
+import (
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"player-bot/engine"
+	"testing"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// the update as the player with the href is sent it
+func sentTo(href string, update cloudbowl.ArenaUpdate) cloudbowl.ArenaUpdate {
+	update.Links.Self.Href = href
+	return update
+}
+
+func isLegal(move cloudbowl.Move, input cloudbowl.ArenaUpdate) bool {
+	for _, legal := range cloudbowl.LegalMoves(input, extractMyState(input)) {
+		if move == legal {
+			return true
+		}
+	}
+	return false
+}
+
+// deals out crowded little arenas with four players, "me" and three opponents, so that walls and other players often
+// get in the way
+func randomArenas(t *testing.T, count int, seed int64) (result []cloudbowl.ArenaUpdate) {
+	rng := rand.New(rand.NewSource(seed))
+	for i := 0; i < count; i++ {
+		state, err := engine.NewGame(5, 4, []string{"me", "a", "b", "c"}, rng)
+		if err != nil {
+			t.Fatalf("NewGame() failed: %v", err)
+		}
+		result = append(result, sentTo("me", state))
+	}
+	return result
+}
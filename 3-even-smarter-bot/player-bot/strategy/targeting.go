@@ -0,0 +1,24 @@
+package strategy
+
+import (
+	"fmt"
+	"player-bot/board"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+const WEIGHTED_TARGETING = "weighted"
+const LEADERBOARD_TARGETING = "leaderboard"
+
+// looks up a target selection policy by name, one of WEIGHTED_TARGETING, which scores every opponent using the weights,
+// or LEADERBOARD_TARGETING, which only goes after the high scorers once we are leading
+func NewTargetSelector(name string, weights board.TargetWeights) (board.TargetSelector, error) {
+	switch name {
+	case WEIGHTED_TARGETING:
+		return board.NewWeightedTargetSelector(weights, cloudbowl.MAX_THROW_DISTANCE), nil
+	case LEADERBOARD_TARGETING:
+		return board.LeaderboardTargetSelector{Percentile: HIGH_SCORING_PERCENTILE, SeekRevenge: SEEK_REVENGE, MaxDistance: cloudbowl.MAX_THROW_DISTANCE}, nil
+	default:
+		return nil, fmt.Errorf("unknown target selector %q, expected %q or %q", name, WEIGHTED_TARGETING, LEADERBOARD_TARGETING)
+	}
+}
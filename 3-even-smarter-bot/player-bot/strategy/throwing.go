@@ -0,0 +1,26 @@
+package strategy
+
+import (
+	"log"
+	"player-bot/board"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+var WEIGH_UP_THROWS = true // only throw when it is expected to score more than lining up a throw for next tick instead
+
+// decides whether to throw at whoever is in front of us, going by how likely they are to still be in our line of fire
+// once everyone has moved. throwing is false if a repositioning move is expected to score more, in which case move is it.
+func throwOrReposition(myState cloudbowl.PlayerState, board board.Board, getMovePrior func(opponent cloudbowl.PlayerState) board.MovePrior) (move cloudbowl.Move, throwing bool) {
+	if !WEIGH_UP_THROWS {
+		return cloudbowl.Throw, true
+	}
+	priors := board.ThrowPriors(myState, cloudbowl.MAX_THROW_DISTANCE, getMovePrior)
+	throwValue, repositionValue, reposition := board.EvaluateThrow(myState, cloudbowl.MAX_THROW_DISTANCE, priors)
+	if reposition == "" || throwValue >= repositionValue {
+		log.Printf("a throw is expected to score %.2f against %.2f for moving, so I am throwing", throwValue, repositionValue)
+		return cloudbowl.Throw, true
+	}
+	log.Printf("a throw is only expected to score %.2f, but moving %v is expected to score %.2f", throwValue, reposition, repositionValue)
+	return reposition, false
+}
@@ -0,0 +1,131 @@
+package tracker
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// somewhere to keep each player's history
+type Store interface {
+	// adds the observation to the end of the player's history, dropping the oldest observations beyond length
+	Append(id string, observation Observation, length int) error
+	// the player's history, oldest first
+	History(id string) ([]Observation, error)
+}
+
+var MAX_REMEMBERED_PLAYERS = 1000 // how many players a MemoryStore keeps histories for before forgetting the least recently seen
+
+// keeps the histories in memory, which is all a single instance of the bot needs
+type MemoryStore struct {
+	mutex     sync.Mutex
+	histories map[string]*memoryHistory
+	appends   int // how many observations have been appended, used to tell which player was seen least recently
+}
+
+type memoryHistory struct {
+	observations []Observation
+	lastAppended int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{histories: make(map[string]*memoryHistory)}
+}
+
+func (store *MemoryStore) Append(id string, observation Observation, length int) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	history, exists := store.histories[id]
+	if !exists {
+		if len(store.histories) >= MAX_REMEMBERED_PLAYERS {
+			store.forgetLeastRecentlySeen()
+		}
+		history = &memoryHistory{}
+		store.histories[id] = history
+	}
+	store.appends++
+	history.lastAppended = store.appends
+	history.observations = append(history.observations, observation)
+	if len(history.observations) > length {
+		history.observations = history.observations[len(history.observations)-length:]
+	}
+	return nil
+}
+
+// only happens when a new player turns up once the store is full, so a scan is cheap enough
+func (store *MemoryStore) forgetLeastRecentlySeen() {
+	oldest := ""
+	for id, history := range store.histories {
+		if oldest == "" || history.lastAppended < store.histories[oldest].lastAppended {
+			oldest = id
+		}
+	}
+	delete(store.histories, oldest)
+}
+
+func (store *MemoryStore) History(id string) ([]Observation, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	history, exists := store.histories[id]
+	if !exists {
+		return nil, nil
+	}
+	return append([]Observation(nil), history.observations...), nil
+}
+
+var HISTORY_EXPIRY_SECONDS = 3600 // forget about players who haven't been seen for a while
+
+// keeps the histories in redis as one list per player, so every instance of the bot shares them
+type RedisStore struct {
+	pool *redis.Pool
+}
+
+func NewRedisStore(pool *redis.Pool) *RedisStore {
+	return &RedisStore{pool: pool}
+}
+
+func historyKey(id string) string {
+	return "history:" + id
+}
+
+func (store *RedisStore) Append(id string, observation Observation, length int) error {
+	value, err := json.Marshal(observation)
+	if err != nil {
+		return err
+	}
+	conn := store.pool.Get()
+	defer conn.Close()
+	key := historyKey(id)
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+	if err := conn.Send("RPUSH", key, value); err != nil {
+		return err
+	}
+	if err := conn.Send("LTRIM", key, -length, -1); err != nil {
+		return err
+	}
+	if err := conn.Send("EXPIRE", key, HISTORY_EXPIRY_SECONDS); err != nil {
+		return err
+	}
+	_, err = conn.Do("EXEC")
+	return err
+}
+
+func (store *RedisStore) History(id string) (result []Observation, err error) {
+	conn := store.pool.Get()
+	defer conn.Close()
+	values, err := redis.ByteSlices(conn.Do("LRANGE", historyKey(id), 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range values {
+		var observation Observation
+		if err := json.Unmarshal(value, &observation); err != nil {
+			return nil, err
+		}
+		result = append(result, observation)
+	}
+	return result, nil
+}
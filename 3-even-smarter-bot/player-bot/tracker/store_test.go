@@ -0,0 +1,35 @@
+package tracker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+func TestMemoryStoreKeepsTheLatestObservations(t *testing.T) {
+	store := NewMemoryStore()
+	for x := 0; x < 5; x++ {
+		store.Append("http://a", Observation{State: cloudbowl.PlayerState{X: x}}, 3)
+	}
+	history, _ := store.History("http://a")
+	if len(history) != 3 || history[0].State.X != 2 || history[2].State.X != 4 {
+		t.Errorf("History() = %v, want the observations at x = 2, 3 and 4", history)
+	}
+}
+
+func TestMemoryStoreForgetsTheLeastRecentlySeen(t *testing.T) {
+	defer func(max int) { MAX_REMEMBERED_PLAYERS = max }(MAX_REMEMBERED_PLAYERS)
+	MAX_REMEMBERED_PLAYERS = 3
+	store := NewMemoryStore()
+	for i := 0; i < 3; i++ {
+		store.Append(fmt.Sprintf("http://%v", i), Observation{}, 1)
+	}
+	store.Append("http://0", Observation{}, 1) // seen again, so http://1 is now the least recently seen
+	store.Append("http://3", Observation{}, 1)
+	for id, want := range map[string]bool{"http://0": true, "http://1": false, "http://2": true, "http://3": true} {
+		if history, _ := store.History(id); (len(history) > 0) != want {
+			t.Errorf("History(%v) = %v, want remembered = %v", id, history, want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package tracker
+
+import (
+	"log"
+	"player-bot/board"
+	"sync"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl/actions"
+)
+
+var HISTORY_LENGTH = 20      // how many updates to remember for each player
+var OBSERVE_QUEUE_LENGTH = 8 // how many updates can wait to be observed in the background before more are dropped
+
+// remembers where every player was, which way they were facing and what their score was over the last few arena
+// updates, so that strategies can tell a player who stands still from one who charges around throwing at everyone
+type Tracker struct {
+	store  Store
+	length int
+
+	// what each player did and who hit whom are worked out from consecutive updates, so the last update is kept here
+	mutex    sync.Mutex
+	previous *cloudbowl.ArenaUpdate
+	ticks    int                                 // how many updates have been observed
+	attacks  map[string]map[string]*attackRecord // indexed by victim, then by thrower
+
+	queue      chan cloudbowl.ArenaUpdate // updates waiting to be observed in the background
+	startQueue sync.Once
+}
+
+type attackRecord struct {
+	hits     float64
+	lastTick int
+}
+
+func New(store Store, length int) *Tracker {
+	return &Tracker{store: store, length: length, attacks: make(map[string]map[string]*attackRecord)}
+}
+
+// a player's state in one arena update, along with what they probably did since the update before it
+type Observation struct {
+	State  cloudbowl.PlayerState `json:"state"`
+	Action *actions.Inference    `json:"action,omitempty"` // nil when we hadn't seen them before
+}
+
+// adds each player's state in the arena update to their history, along with what they did since the last update, and
+// works out who hit whom
+func (tracker *Tracker) Observe(update cloudbowl.ArenaUpdate) {
+	tracker.mutex.Lock()
+	var inferences map[string]actions.Inference
+	tracker.ticks++
+	if tracker.previous != nil {
+		inferences = actions.Infer(*tracker.previous, update)
+		for _, hit := range actions.AttributeHits(*tracker.previous, update) {
+			if tracker.attacks[hit.Victim] == nil {
+				tracker.attacks[hit.Victim] = make(map[string]*attackRecord)
+			}
+			record, exists := tracker.attacks[hit.Victim][hit.Thrower]
+			if !exists {
+				record = &attackRecord{}
+				tracker.attacks[hit.Victim][hit.Thrower] = record
+			}
+			record.hits += hit.Confidence
+			record.lastTick = tracker.ticks
+		}
+	}
+	tracker.previous = &update
+	tracker.mutex.Unlock()
+
+	for id, state := range update.Arena.State {
+		state.Id = id
+		observation := Observation{State: state}
+		if inference, found := inferences[id]; found {
+			observation.Action = &inference
+		}
+		if err := tracker.store.Append(id, observation, tracker.length); err != nil {
+			log.Printf("WARN: failed to track %v: %v", id, err)
+		}
+	}
+}
+
+// observes the update on another goroutine, so whoever is waiting on a move doesn't also wait on the store. Updates are
+// observed one at a time in the order they arrive, and dropped if too many are already waiting.
+func (tracker *Tracker) ObserveInBackground(update cloudbowl.ArenaUpdate) {
+	tracker.startQueue.Do(func() {
+		tracker.queue = make(chan cloudbowl.ArenaUpdate, OBSERVE_QUEUE_LENGTH)
+		go func() {
+			for update := range tracker.queue {
+				tracker.Observe(update)
+			}
+		}()
+	})
+	select {
+	case tracker.queue <- update:
+	default:
+		log.Printf("WARN: dropped an arena update, %v are already waiting to be tracked", OBSERVE_QUEUE_LENGTH)
+	}
+}
+
+// lists everyone who has hit the victim since we started watching, indexed by the thrower's Id
+func (tracker *Tracker) Grudges(victim string) map[string]board.Grudge {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	result := make(map[string]board.Grudge, len(tracker.attacks[victim]))
+	for thrower, record := range tracker.attacks[victim] {
+		result[thrower] = board.Grudge{Hits: record.hits, TicksSince: tracker.ticks - record.lastTick}
+	}
+	return result
+}
+
+// the player's remembered observations, oldest first
+func (tracker *Tracker) History(id string) []Observation {
+	history, err := tracker.store.History(id)
+	if err != nil {
+		log.Printf("WARN: failed to read the history of %v: %v", id, err)
+		return nil
+	}
+	return history
+}
+
+// how a player has behaved over the updates we remember. The arena only sends states, so the traits go by what
+// actions.Infer made of each update, each counting for as much as it was sure of.
+type Traits struct {
+	Observations   int     // how many updates with an inferred action the traits are based on
+	MovementRate   float64 // the fraction of updates in which the player walked forward
+	TurnRate       float64 // the fraction of updates in which the player turned
+	TurnBias       float64 // from -1 when every turn was to the left to 1 when every turn was to the right
+	ThrowFrequency float64 // the fraction of updates in which the player threw, whether or not they hit anyone
+	HitRate        float64 // the fraction of updates in which the player was hit
+}
+
+// works out the player's traits from their history, found is false if there isn't enough history to go on yet
+func (tracker *Tracker) Traits(id string) (result Traits, found bool) {
+	var moves, lefts, rights, throws, hits float64
+	for _, observation := range tracker.History(id) {
+		if observation.Action == nil {
+			continue
+		}
+		result.Observations++
+		confidence := observation.Action.Confidence
+		switch observation.Action.Action {
+		case actions.Forward:
+			moves += confidence
+		case actions.TurnLeft:
+			lefts += confidence
+		case actions.TurnRight:
+			rights += confidence
+		case actions.Throw:
+			throws += confidence
+		}
+		if observation.State.WasHit {
+			hits++
+		}
+	}
+	if result.Observations == 0 {
+		return result, false
+	}
+	observations := float64(result.Observations)
+	result.MovementRate = moves / observations
+	result.TurnRate = (lefts + rights) / observations
+	if lefts+rights > 0 {
+		result.TurnBias = (rights - lefts) / (lefts + rights)
+	}
+	result.ThrowFrequency = throws / observations
+	result.HitRate = hits / observations
+	return result, true
+}
+
+var PRIOR_OBSERVATIONS = 5.0 // how many observations board.DEFAULT_MOVE_PRIOR is worth when blending it with a player's traits
+
+// how likely the player is to make each move next, going by how they have behaved so far. With little history to go on
+// it stays close to board.DEFAULT_MOVE_PRIOR.
+func (tracker *Tracker) MovePrior(id string) board.MovePrior {
+	traits, found := tracker.Traits(id)
+	if !found {
+		return board.DEFAULT_MOVE_PRIOR
+	}
+	return traits.MovePrior(PRIOR_OBSERVATIONS)
+}
+
+// the chance of each move implied by the traits, blended with board.DEFAULT_MOVE_PRIOR as if it had been seen
+// priorObservations times
+func (traits Traits) MovePrior(priorObservations float64) board.MovePrior {
+	observed := board.MovePrior{
+		Forward:   traits.MovementRate,
+		TurnLeft:  traits.TurnRate * (1 - traits.TurnBias) / 2,
+		TurnRight: traits.TurnRate * (1 + traits.TurnBias) / 2,
+		Throw:     traits.ThrowFrequency,
+	}
+	// traits that weren't worked out by Traits may add up to more than every tick, so make sure the chances don't either
+	if total := observed.Forward + observed.TurnLeft + observed.TurnRight + observed.Throw; total > 1 {
+		observed = board.MovePrior{Forward: observed.Forward / total, TurnLeft: observed.TurnLeft / total, TurnRight: observed.TurnRight / total, Throw: observed.Throw / total}
+	}
+	weight := float64(traits.Observations) / (float64(traits.Observations) + priorObservations)
+	blend := func(prior float64, observed float64) float64 { return (1-weight)*prior + weight*observed }
+	prior := board.DEFAULT_MOVE_PRIOR
+	return board.MovePrior{
+		Forward:   blend(prior.Forward, observed.Forward),
+		TurnLeft:  blend(prior.TurnLeft, observed.TurnLeft),
+		TurnRight: blend(prior.TurnRight, observed.TurnRight),
+		Throw:     blend(prior.Throw, observed.Throw),
+	}
+}
@@ -0,0 +1,121 @@
+package tracker
+
+import (
+	"io"
+	"log"
+	"math"
+	"os"
+	"player-bot/board"
+	"testing"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl/actions"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func closeTo(a board.MovePrior, b board.MovePrior) bool {
+	near := func(x float64, y float64) bool { return math.Abs(x-y) < 1e-9 }
+	return near(a.Forward, b.Forward) && near(a.TurnLeft, b.TurnLeft) && near(a.TurnRight, b.TurnRight) && near(a.Throw, b.Throw)
+}
+
+func TestTraitsMovePrior(t *testing.T) {
+	prior := board.DEFAULT_MOVE_PRIOR
+	tests := []struct {
+		name              string
+		traits            Traits
+		priorObservations float64
+		want              board.MovePrior
+	}{
+		{
+			name:              "nothing observed",
+			traits:            Traits{},
+			priorObservations: 5,
+			want:              prior,
+		},
+		{
+			name:              "only what was observed",
+			traits:            Traits{Observations: 10, MovementRate: 0.5, TurnRate: 0.2, TurnBias: 1, ThrowFrequency: 0.1},
+			priorObservations: 0,
+			want:              board.MovePrior{Forward: 0.5, TurnLeft: 0, TurnRight: 0.2, Throw: 0.1},
+		},
+		{
+			name:              "turns shared out by bias",
+			traits:            Traits{Observations: 10, TurnRate: 0.4, TurnBias: -0.5},
+			priorObservations: 0,
+			want:              board.MovePrior{TurnLeft: 0.3, TurnRight: 0.1},
+		},
+		{
+			name:              "as much history as prior",
+			traits:            Traits{Observations: 5, MovementRate: 1},
+			priorObservations: 5,
+			want:              board.MovePrior{Forward: (prior.Forward + 1) / 2, TurnLeft: prior.TurnLeft / 2, TurnRight: prior.TurnRight / 2, Throw: prior.Throw / 2},
+		},
+		{
+			name:              "missed updates adding up to more than every tick",
+			traits:            Traits{Observations: 4, MovementRate: 1, TurnRate: 1, ThrowFrequency: 0},
+			priorObservations: 0,
+			want:              board.MovePrior{Forward: 0.5, TurnLeft: 0.25, TurnRight: 0.25},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.traits.MovePrior(test.priorObservations); !closeTo(got, test.want) {
+				t.Errorf("MovePrior() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTrackerMovePrior(t *testing.T) {
+	tracker := New(NewMemoryStore(), HISTORY_LENGTH)
+	if got := tracker.MovePrior("http://a"); got != board.DEFAULT_MOVE_PRIOR {
+		t.Errorf("MovePrior() = %+v before anything was observed, want the default", got)
+	}
+	// a player who walks east every tick
+	for x := 0; x < 3; x++ {
+		tracker.Observe(cloudbowl.NewArenaUpdate(7, 5, map[string]cloudbowl.PlayerState{"http://a": {X: x, Y: 2, Direction: cloudbowl.East}}))
+	}
+	weight := 2 / (2 + PRIOR_OBSERVATIONS)
+	prior := board.DEFAULT_MOVE_PRIOR
+	want := board.MovePrior{
+		Forward:   (1-weight)*prior.Forward + weight,
+		TurnLeft:  (1 - weight) * prior.TurnLeft,
+		TurnRight: (1 - weight) * prior.TurnRight,
+		Throw:     (1 - weight) * prior.Throw,
+	}
+	if got := tracker.MovePrior("http://a"); !closeTo(got, want) {
+		t.Errorf("MovePrior() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTrackerTraits(t *testing.T) {
+	tracker := New(NewMemoryStore(), HISTORY_LENGTH)
+	// a throws at b every tick, while c walks south and d turns left
+	for tick := 0; tick < 4; tick++ {
+		tracker.Observe(cloudbowl.NewArenaUpdate(7, 5, map[string]cloudbowl.PlayerState{
+			"http://a": {X: 0, Y: 0, Direction: cloudbowl.East, Score: tick},
+			"http://b": {X: 1, Y: 0, Direction: cloudbowl.South, Score: -tick, WasHit: tick > 0},
+			"http://c": {X: 6, Y: tick, Direction: cloudbowl.South},
+			"http://d": {X: 3, Y: 4, Direction: []cloudbowl.Direction{cloudbowl.North, cloudbowl.West, cloudbowl.South, cloudbowl.East}[tick]},
+		}))
+	}
+	tests := map[string]Traits{
+		"http://a": {Observations: 3, ThrowFrequency: 1},
+		// standing still with nobody in range looks like a throw that missed as much as anything
+		"http://b": {Observations: 3, ThrowFrequency: actions.PRIOR_MISSED_THROW / (actions.PRIOR_IDLE + actions.PRIOR_MISSED_THROW), HitRate: 1},
+		"http://c": {Observations: 3, MovementRate: 1},
+		"http://d": {Observations: 3, TurnRate: 1, TurnBias: -1},
+	}
+	for id, want := range tests {
+		if got, found := tracker.Traits(id); !found || got != want {
+			t.Errorf("Traits(%v) = %+v, %v, want %+v", id, got, found, want)
+		}
+	}
+	if _, found := tracker.Traits("http://e"); found {
+		t.Errorf("Traits() found traits for a player who was never seen")
+	}
+}
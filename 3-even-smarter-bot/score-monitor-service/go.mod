@@ -30,3 +30,7 @@ require (
 	google.golang.org/grpc v1.45.0 // indirect
 	google.golang.org/protobuf v1.28.0 // indirect
 )
+
+require github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl v0.0.0-00010101000000-000000000000
+
+replace github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl => ../../cloudbowl
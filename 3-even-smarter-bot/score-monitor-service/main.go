@@ -2,17 +2,15 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"net/http"
-	"os"
 
 	"contrib.go.opencensus.io/exporter/stackdriver"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 
-	"score-monitor-service/shared"
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
 )
 
 var (
@@ -35,36 +33,20 @@ func init() {
 
 func main() {
 	http.HandleFunc("/", EventProcessor)
-	// Determine port for HTTP service.
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
 	// Start HTTP server.
-	log.Printf("Listening on port %s", port)
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
+	if err := cloudbowl.ListenAndServe(nil); err != nil {
 		log.Fatal(err)
 	}
 }
 
 func EventProcessor(w http.ResponseWriter, req *http.Request) {
-	var arenaUpdate shared.ArenaUpdate
-	var pubsubMessageEvent shared.PubSubMessageEvent
 	defer req.Body.Close()
-	d := json.NewDecoder(req.Body)
-	// d.DisallowUnknownFields()
-	if err := d.Decode(&pubsubMessageEvent); err != nil {
-		log.Printf("WARN: failed to decode PubSubMessageEvent in response body: %v", err)
+	arenaUpdate, err := cloudbowl.DecodeArenaUpdateEvent(req.Body)
+	if err != nil {
+		log.Printf("WARN: %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	// log.Printf("event data: byte[] %v", pubsubMessageEvent.Message.Data)
-	// log.Printf("event data: string %v", string(pubsubMessageEvent.Message.Data))
-
-	err := json.Unmarshal(pubsubMessageEvent.Message.Data, &arenaUpdate)
-	if err != nil {
-		log.Fatalf("fatal error parsing ArenaUpdate: %v", err)
-	}
 	exporter, err := stackdriver.NewExporter(stackdriver.Options{})
 	if err != nil {
 		log.Fatal(err)
@@ -1,13 +0,0 @@
-package shared
-
-type ArenaUpdate struct {
-	Links struct {
-		Self struct {
-			Href string `json:"href"`
-		} `json:"self"`
-	} `json:"_links"`
-	Arena struct {
-		Dimensions []int                  `json:"dims"`
-		State      map[string]PlayerState `json:"state"`
-	} `json:"arena"`
-}
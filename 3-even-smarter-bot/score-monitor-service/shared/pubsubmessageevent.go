@@ -1,9 +0,0 @@
-package shared
-
-type PubSubMessageEvent struct {
-	Message struct {
-		Data []byte `json:"data,omitempty"`
-		ID   string `json:"id"`
-	} `json:"message"`
-	Subscription string `json:"subscription"`
-}
@@ -0,0 +1,123 @@
+// Package actions works out what each player probably did between two arena updates. The game only ever sends the
+// state of the arena, so the moves have to be inferred from what changed.
+package actions
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// something a player can do in a tick: one of the moves, or nothing at all, e.g. because their bot timed out
+type Action string
+
+const (
+	Forward   = Action(cloudbowl.Forward)
+	TurnLeft  = Action(cloudbowl.TurnLeft)
+	TurnRight = Action(cloudbowl.TurnRight)
+	Throw     = Action(cloudbowl.Throw)
+	Idle      = Action("idle")
+)
+
+// the move that makes up the action, ok is false for Idle
+func (action Action) Move() (move cloudbowl.Move, ok bool) {
+	move = cloudbowl.Move(action)
+	return move, move.Valid()
+}
+
+func (action Action) String() string {
+	return string(action)
+}
+
+// how much more likely each action is than the others when nothing changed, before taking into account which of them
+// could have happened. Bots rarely time out, but throwing with nobody in range and walking into a wall are both common.
+var PRIOR_IDLE = 1.0
+var PRIOR_MISSED_THROW = 2.0
+var PRIOR_BLOCKED_FORWARD = 2.0
+
+// what a player probably did between two updates
+type Inference struct {
+	Action     Action  `json:"action"`
+	Confidence float64 `json:"confidence"` // from 0, a guess because the updates don't add up, to 1, certain
+}
+
+func (inference Inference) String() string {
+	return fmt.Sprintf("%v (%.0f%%)", inference.Action, 100*inference.Confidence)
+}
+
+// works out what each player in both updates probably did to get from one to the other, indexed by the player's Id.
+// Players who joined or left in between are left out.
+func Infer(before cloudbowl.ArenaUpdate, after cloudbowl.ArenaUpdate) map[string]Inference {
+	result := make(map[string]Inference)
+	ids := make([]string, 0, len(after.Arena.State))
+	for id := range after.Arena.State {
+		if _, existed := before.Arena.State[id]; existed {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		result[id] = infer(id, before, after)
+	}
+	return result
+}
+
+func infer(id string, before cloudbowl.ArenaUpdate, after cloudbowl.ArenaUpdate) Inference {
+	was := before.Arena.State[id]
+	now := after.Arena.State[id]
+
+	// only one thing can happen in a tick, so a change of position or direction gives it away
+	if was.X != now.X || was.Y != now.Y {
+		dx, dy := was.Direction.Step()
+		if was.Direction == now.Direction && now.X == was.X+dx && now.Y == was.Y+dy {
+			return Inference{Action: Forward, Confidence: 1}
+		}
+		return Inference{Action: Forward, Confidence: 0} // an update must have been missed
+	}
+	switch now.Direction {
+	case was.Direction:
+	case was.Direction.RotateLeft():
+		return Inference{Action: TurnLeft, Confidence: 1}
+	case was.Direction.RotateRight():
+		return Inference{Action: TurnRight, Confidence: 1}
+	default: // turned all the way round, so an update must have been missed
+		return Inference{Action: TurnRight, Confidence: 0}
+	}
+
+	// being hit loses a point, so any other change to the score came from hitting someone
+	gained := now.Score - was.Score
+	if now.WasHit {
+		gained++
+	}
+	if gained > 0 {
+		return Inference{Action: Throw, Confidence: 1}
+	}
+
+	// nothing changed, which could be a throw that missed, a step forward into something, or no move at all
+	weights := map[Action]float64{Idle: PRIOR_IDLE}
+	if _, found := firstPlayerInLine(id, after); !found { // a throw would have hit whoever is in front of them
+		weights[Throw] = PRIOR_MISSED_THROW
+	}
+	if isForwardBlocked(was, before, after) {
+		weights[Forward] = PRIOR_BLOCKED_FORWARD
+	}
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	result := Inference{Action: Idle}
+	for _, action := range []Action{Forward, Throw, Idle} { // a fixed order so ties always go the same way
+		if weight, possible := weights[action]; possible && weight > weights[result.Action]*(1+1e-9) {
+			result.Action = action
+		}
+	}
+	result.Confidence = weights[result.Action] / total
+	return result
+}
+
+// determines if a step forward would have been stopped by a wall or by someone standing in the way, before or after
+// they moved since the game applies forward moves one player at a time
+func isForwardBlocked(player cloudbowl.PlayerState, before cloudbowl.ArenaUpdate, after cloudbowl.ArenaUpdate) bool {
+	return cloudbowl.IsForwardBlocked(before, player) || cloudbowl.IsForwardBlocked(after, player)
+}
@@ -0,0 +1,155 @@
+package actions
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+func TestInfer(t *testing.T) {
+	tests := []struct {
+		name   string
+		before map[string]cloudbowl.PlayerState
+		after  map[string]cloudbowl.PlayerState
+		want   map[string]Inference
+	}{
+		{
+			name:   "walked forward",
+			before: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.East}},
+			after:  map[string]cloudbowl.PlayerState{"a": {X: 3, Y: 2, Direction: cloudbowl.East}},
+			want:   map[string]Inference{"a": {Action: Forward, Confidence: 1}},
+		},
+		{
+			name:   "moved further than one step",
+			before: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.East}},
+			after:  map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 3, Direction: cloudbowl.South}},
+			want:   map[string]Inference{"a": {Action: Forward, Confidence: 0}},
+		},
+		{
+			name:   "turned left",
+			before: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.North}},
+			after:  map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.West}},
+			want:   map[string]Inference{"a": {Action: TurnLeft, Confidence: 1}},
+		},
+		{
+			name:   "turned right",
+			before: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.North}},
+			after:  map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.East}},
+			want:   map[string]Inference{"a": {Action: TurnRight, Confidence: 1}},
+		},
+		{
+			name:   "turned all the way round",
+			before: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.North}},
+			after:  map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.South}},
+			want:   map[string]Inference{"a": {Action: TurnRight, Confidence: 0}},
+		},
+		{
+			name: "hit someone",
+			before: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.West},
+			},
+			after: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East, Score: 1},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.West, Score: -1, WasHit: true},
+			},
+			want: map[string]Inference{
+				"a": {Action: Throw, Confidence: 1},
+				// b could have thrown too, but then a would have been hit
+				"b": {Action: Idle, Confidence: 1},
+			},
+		},
+		{
+			name: "hit someone while being hit",
+			before: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.West},
+			},
+			after: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East, WasHit: true},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.West, WasHit: true},
+			},
+			want: map[string]Inference{"a": {Action: Throw, Confidence: 1}, "b": {Action: Throw, Confidence: 1}},
+		},
+		{
+			name:   "nothing changed with nobody in range",
+			before: map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.East}},
+			after:  map[string]cloudbowl.PlayerState{"a": {X: 2, Y: 2, Direction: cloudbowl.East}},
+			want:   map[string]Inference{"a": {Action: Throw, Confidence: PRIOR_MISSED_THROW / (PRIOR_IDLE + PRIOR_MISSED_THROW)}},
+		},
+		{
+			// a step into the wall and a throw at nobody are as likely as each other, so the tie goes to walking
+			name:   "nothing changed facing a wall",
+			before: map[string]cloudbowl.PlayerState{"a": {X: 0, Y: 0, Direction: cloudbowl.North}},
+			after:  map[string]cloudbowl.PlayerState{"a": {X: 0, Y: 0, Direction: cloudbowl.North}},
+			want: map[string]Inference{"a": {Action: Forward,
+				Confidence: PRIOR_BLOCKED_FORWARD / (PRIOR_IDLE + PRIOR_MISSED_THROW + PRIOR_BLOCKED_FORWARD)}},
+		},
+		{
+			name: "nothing changed facing someone next door",
+			before: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 1, Y: 0, Direction: cloudbowl.South},
+			},
+			after: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 1, Y: 0, Direction: cloudbowl.West},
+			},
+			want: map[string]Inference{
+				"a": {Action: Forward, Confidence: PRIOR_BLOCKED_FORWARD / (PRIOR_IDLE + PRIOR_BLOCKED_FORWARD)},
+				"b": {Action: TurnRight, Confidence: 1},
+			},
+		},
+		{
+			name: "nothing changed facing someone in range",
+			before: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 3, Y: 0, Direction: cloudbowl.South},
+			},
+			after: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 3, Y: 0, Direction: cloudbowl.West},
+			},
+			want: map[string]Inference{
+				"a": {Action: Idle, Confidence: 1},
+				"b": {Action: TurnRight, Confidence: 1},
+			},
+		},
+		{
+			// forward moves are made one player at a time, so a may have tried to step after b got there
+			name: "someone stepped in the way",
+			before: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 2, Direction: cloudbowl.East},
+				"b": {X: 1, Y: 1, Direction: cloudbowl.South},
+			},
+			after: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 2, Direction: cloudbowl.East},
+				"b": {X: 1, Y: 2, Direction: cloudbowl.South},
+			},
+			want: map[string]Inference{
+				"a": {Action: Forward, Confidence: PRIOR_BLOCKED_FORWARD / (PRIOR_IDLE + PRIOR_BLOCKED_FORWARD)},
+				"b": {Action: Forward, Confidence: 1},
+			},
+		},
+		{
+			name: "players who joined or left",
+			before: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.South},
+				"b": {X: 6, Y: 4, Direction: cloudbowl.North},
+			},
+			after: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 1, Direction: cloudbowl.South},
+				"c": {X: 3, Y: 3, Direction: cloudbowl.West},
+			},
+			want: map[string]Inference{"a": {Action: Forward, Confidence: 1}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Infer(cloudbowl.NewArenaUpdate(7, 5, test.before), cloudbowl.NewArenaUpdate(7, 5, test.after)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Infer() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
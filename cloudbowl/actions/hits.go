@@ -0,0 +1,60 @@
+package actions
+
+import (
+	"sort"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+// one player hitting another
+type Hit struct {
+	Thrower    string  `json:"thrower"`
+	Victim     string  `json:"victim"`
+	Confidence float64 `json:"confidence"` // from 0 to 1, a victim with several possible throwers shares it out between them
+}
+
+// works out who hit whom between two updates. A player who gained a point must have hit the first player in their line
+// of fire, since throws land after everyone has moved. Anyone hit that can't be explained that way, e.g. because an update
+// was missed, is put down to the players who had them in their line of fire, shared equally.
+func AttributeHits(before cloudbowl.ArenaUpdate, after cloudbowl.ArenaUpdate) (result []Hit) {
+	inferences := Infer(before, after)
+	ids := make([]string, 0, len(inferences))
+	for id := range inferences {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	explained := make(map[string]bool)
+	for _, id := range ids {
+		if inference := inferences[id]; inference.Action != Throw || inference.Confidence < 1 {
+			continue
+		}
+		victim, found := firstPlayerInLine(id, after)
+		if found && after.Arena.State[victim].WasHit {
+			result = append(result, Hit{Thrower: id, Victim: victim, Confidence: 1})
+			explained[victim] = true
+		}
+	}
+
+	for _, victim := range ids {
+		if !after.Arena.State[victim].WasHit || explained[victim] {
+			continue
+		}
+		var suspects []string
+		for _, id := range ids {
+			if target, found := firstPlayerInLine(id, after); found && target == victim {
+				suspects = append(suspects, id)
+			}
+		}
+		for _, suspect := range suspects {
+			result = append(result, Hit{Thrower: suspect, Victim: victim, Confidence: 1 / float64(len(suspects))})
+		}
+	}
+	return result
+}
+
+// finds who a throw by the player would hit in the update
+func firstPlayerInLine(id string, update cloudbowl.ArenaUpdate) (victim string, found bool) {
+	thrower := update.Arena.State[id]
+	return cloudbowl.FirstPlayerInLine(update, thrower.X, thrower.Y, thrower.Direction, cloudbowl.MAX_THROW_DISTANCE)
+}
@@ -0,0 +1,126 @@
+package actions
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl"
+)
+
+func TestAttributeHits(t *testing.T) {
+	tests := []struct {
+		name   string
+		before map[string]cloudbowl.PlayerState
+		after  map[string]cloudbowl.PlayerState
+		want   []Hit
+	}{
+		{
+			name: "nobody hit",
+			before: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.North},
+			},
+			after: map[string]cloudbowl.PlayerState{
+				"a": {X: 1, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.West},
+			},
+			want: nil,
+		},
+		{
+			name: "thrower gained a point",
+			before: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.North},
+			},
+			after: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East, Score: 1},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.North, Score: -1, WasHit: true},
+			},
+			want: []Hit{{Thrower: "a", Victim: "b", Confidence: 1}},
+		},
+		{
+			name: "the first player in line takes the hit",
+			before: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 1, Y: 0, Direction: cloudbowl.South},
+				"c": {X: 2, Y: 0, Direction: cloudbowl.North},
+			},
+			after: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East, Score: 1},
+				"b": {X: 1, Y: 0, Direction: cloudbowl.South, Score: -1, WasHit: true},
+				"c": {X: 2, Y: 0, Direction: cloudbowl.North},
+			},
+			want: []Hit{{Thrower: "a", Victim: "b", Confidence: 1}},
+		},
+		{
+			name: "throws land after everyone has moved",
+			before: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 1, Direction: cloudbowl.North},
+			},
+			after: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East, Score: 1},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.North, Score: -1, WasHit: true},
+			},
+			want: []Hit{{Thrower: "a", Victim: "b", Confidence: 1}},
+		},
+		{
+			name: "a hit while hitting someone else",
+			before: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.West},
+			},
+			after: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East, WasHit: true},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.West, WasHit: true},
+			},
+			want: []Hit{{Thrower: "a", Victim: "b", Confidence: 1}, {Thrower: "b", Victim: "a", Confidence: 1}},
+		},
+		{
+			name: "one of two suspects, shared equally",
+			before: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 2, Direction: cloudbowl.East, Score: 1},
+				"b": {X: 2, Y: 2, Direction: cloudbowl.South},
+				"c": {X: 4, Y: 2, Direction: cloudbowl.West, Score: 1},
+			},
+			// a missed update hides who gained the point
+			after: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 2, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 2, Direction: cloudbowl.South, Score: -1, WasHit: true},
+				"c": {X: 4, Y: 2, Direction: cloudbowl.West},
+			},
+			want: []Hit{{Thrower: "a", Victim: "b", Confidence: 0.5}, {Thrower: "c", Victim: "b", Confidence: 0.5}},
+		},
+		{
+			name: "nobody in range to blame",
+			before: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 2, Direction: cloudbowl.East},
+				"b": {X: 4, Y: 2, Direction: cloudbowl.South},
+			},
+			after: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 2, Direction: cloudbowl.East},
+				"b": {X: 4, Y: 2, Direction: cloudbowl.South, Score: -1, WasHit: true},
+			},
+			want: nil,
+		},
+		{
+			name: "a point gained without anyone in line being hit",
+			before: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.North},
+			},
+			after: map[string]cloudbowl.PlayerState{
+				"a": {X: 0, Y: 0, Direction: cloudbowl.East, Score: 1},
+				"b": {X: 2, Y: 0, Direction: cloudbowl.North},
+			},
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := AttributeHits(cloudbowl.NewArenaUpdate(7, 5, test.before), cloudbowl.NewArenaUpdate(7, 5, test.after)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("AttributeHits() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
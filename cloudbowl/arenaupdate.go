@@ -0,0 +1,33 @@
+package cloudbowl
+
+type ArenaUpdate struct {
+	Links struct {
+		Self struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"_links"`
+	Arena struct {
+		Dimensions []int                  `json:"dims"`
+		State      map[string]PlayerState `json:"state"`
+	} `json:"arena"`
+}
+
+// fills in each player's Id from the href their state is keyed by, since the game doesn't send it
+func (update *ArenaUpdate) AssignPlayerIds() {
+	for id, player := range update.Arena.State {
+		player.Id = id
+		update.Arena.State[id] = player
+	}
+}
+
+// creates an update for a width x height arena holding the players, indexed by href, with each player's Id filled in.
+// The players are copied, so the map passed in is left as it is.
+func NewArenaUpdate(width int, height int, players map[string]PlayerState) (result ArenaUpdate) {
+	result.Arena.Dimensions = []int{width, height}
+	result.Arena.State = make(map[string]PlayerState, len(players))
+	for id, player := range players {
+		result.Arena.State[id] = player
+	}
+	result.AssignPlayerIds()
+	return result
+}
@@ -0,0 +1,100 @@
+package cloudbowl
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// one of the eight compass points, used to describe where one square is relative to another
+type Bearing string
+
+const (
+	BearingNorth     Bearing = "N"
+	BearingNorthEast Bearing = "NE"
+	BearingEast      Bearing = "E"
+	BearingSouthEast Bearing = "SE"
+	BearingSouth     Bearing = "S"
+	BearingSouthWest Bearing = "SW"
+	BearingWest      Bearing = "W"
+	BearingNorthWest Bearing = "NW"
+)
+
+// every bearing, in clockwise order starting from north
+var Bearings = []Bearing{BearingNorth, BearingNorthEast, BearingEast, BearingSouthEast, BearingSouth, BearingSouthWest, BearingWest, BearingNorthWest}
+
+func ParseBearing(value string) (Bearing, error) {
+	bearing := Bearing(value)
+	if !bearing.Valid() {
+		return "", fmt.Errorf("invalid bearing %q, expected one of %v", value, Bearings)
+	}
+	return bearing, nil
+}
+
+// the bearing of the square at toX,toY as seen from fromX,fromY. A square in the same column counts as
+// north or south and a square in the same row as east or west, anything else is one of the diagonals.
+func BearingBetween(fromX int, fromY int, toX int, toY int) Bearing {
+	if fromX == toX {
+		if fromY > toY {
+			return BearingNorth
+		}
+		return BearingSouth
+	} else if fromY == toY {
+		if fromX > toX {
+			return BearingWest
+		}
+		return BearingEast
+	} else if fromY > toY {
+		if fromX > toX {
+			return BearingNorthWest
+		}
+		return BearingNorthEast
+	} else { // fromY < toY
+		if fromX > toX {
+			return BearingSouthWest
+		}
+		return BearingSouthEast
+	}
+}
+
+func (bearing Bearing) Valid() bool {
+	return bearing.index() >= 0
+}
+
+// how many eighths of a full turn clockwise it takes to get from this bearing to the other one, from 0 to 7.
+// Both bearings are expected to be valid.
+func (bearing Bearing) EighthsClockwiseTo(other Bearing) int {
+	return (other.index() - bearing.index() + len(Bearings)) % len(Bearings)
+}
+
+func (bearing Bearing) index() int {
+	for i, b := range Bearings {
+		if b == bearing {
+			return i
+		}
+	}
+	return -1
+}
+
+func (bearing Bearing) String() string {
+	return string(bearing)
+}
+
+func (bearing Bearing) MarshalJSON() ([]byte, error) {
+	if !bearing.Valid() {
+		return nil, fmt.Errorf("invalid bearing %q", string(bearing))
+	}
+	return json.Marshal(string(bearing))
+}
+
+func (bearing *Bearing) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := ParseBearing(value)
+	if err != nil {
+		return err
+	}
+	*bearing = parsed
+	return nil
+}
@@ -0,0 +1,109 @@
+package cloudbowl
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// the way a player is facing, north being towards y == 0
+type Direction string
+
+const (
+	North Direction = "N"
+	East  Direction = "E"
+	South Direction = "S"
+	West  Direction = "W"
+)
+
+// every direction a player can face, in clockwise order
+var Directions = []Direction{North, East, South, West}
+
+func ParseDirection(value string) (Direction, error) {
+	direction := Direction(value)
+	if !direction.Valid() {
+		return "", fmt.Errorf("invalid direction %q, expected one of %v", value, Directions)
+	}
+	return direction, nil
+}
+
+func (direction Direction) Valid() bool {
+	switch direction {
+	case North, East, South, West:
+		return true
+	}
+	return false
+}
+
+// the direction a player ends up facing after turning left, invalid directions are returned unchanged
+func (direction Direction) RotateLeft() Direction {
+	switch direction {
+	case North:
+		return West
+	case East:
+		return North
+	case South:
+		return East
+	case West:
+		return South
+	}
+	return direction
+}
+
+// the direction a player ends up facing after turning right, invalid directions are returned unchanged
+func (direction Direction) RotateRight() Direction {
+	switch direction {
+	case North:
+		return East
+	case East:
+		return South
+	case South:
+		return West
+	case West:
+		return North
+	}
+	return direction
+}
+
+// the change in x and y for a single step forward, invalid directions don't move at all
+func (direction Direction) Step() (dx int, dy int) {
+	switch direction {
+	case North:
+		return 0, -1
+	case East:
+		return 1, 0
+	case South:
+		return 0, 1
+	case West:
+		return -1, 0
+	}
+	return 0, 0
+}
+
+// the compass bearing pointing the same way as this direction
+func (direction Direction) Bearing() Bearing {
+	return Bearing(direction)
+}
+
+func (direction Direction) String() string {
+	return string(direction)
+}
+
+func (direction Direction) MarshalJSON() ([]byte, error) {
+	if !direction.Valid() {
+		return nil, fmt.Errorf("invalid direction %q", string(direction))
+	}
+	return json.Marshal(string(direction))
+}
+
+func (direction *Direction) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := ParseDirection(value)
+	if err != nil {
+		return err
+	}
+	*direction = parsed
+	return nil
+}
@@ -0,0 +1,3 @@
+module github.com/craigsdickson/cloud-run-hackathon-go/cloudbowl
+
+go 1.17
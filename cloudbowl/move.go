@@ -0,0 +1,59 @@
+package cloudbowl
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// an action a player can take on their turn
+type Move string
+
+const (
+	Forward   Move = "F"
+	TurnLeft  Move = "L"
+	TurnRight Move = "R"
+	Throw     Move = "T"
+)
+
+// every move a player can make
+var Moves = []Move{Forward, TurnLeft, TurnRight, Throw}
+
+func ParseMove(value string) (Move, error) {
+	move := Move(value)
+	if !move.Valid() {
+		return "", fmt.Errorf("invalid move %q, expected one of %v", value, Moves)
+	}
+	return move, nil
+}
+
+func (move Move) Valid() bool {
+	switch move {
+	case Forward, TurnLeft, TurnRight, Throw:
+		return true
+	}
+	return false
+}
+
+func (move Move) String() string {
+	return string(move)
+}
+
+func (move Move) MarshalJSON() ([]byte, error) {
+	if !move.Valid() {
+		return nil, fmt.Errorf("invalid move %q", string(move))
+	}
+	return json.Marshal(string(move))
+}
+
+func (move *Move) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := ParseMove(value)
+	if err != nil {
+		return err
+	}
+	*move = parsed
+	return nil
+}
@@ -0,0 +1,12 @@
+package cloudbowl
+
+type PlayerState struct {
+	// the href the player is known by in the arena, which the game itself doesn't send. Arena updates are decoded
+	// with it filled in from the keys of the state, and it is left out of the JSON when empty.
+	Id        string    `json:",omitempty"`
+	X         int       `json:"x"`
+	Y         int       `json:"y"`
+	Direction Direction `json:"direction"`
+	WasHit    bool      `json:"wasHit"`
+	Score     int       `json:"score"`
+}
@@ -0,0 +1,18 @@
+package cloudbowl
+
+import "time"
+
+// the attribute the player bot sets on each arena update it publishes, holding when it received the update in
+// milliseconds since the Unix epoch. Pub/Sub can deliver messages out of order and more than once, so services that
+// compare consecutive updates use it to tell which came first.
+var RECEIVED_ATTRIBUTE = "received"
+
+type PubSubMessageEvent struct {
+	Message struct {
+		Data        []byte            `json:"data,omitempty"`
+		Attributes  map[string]string `json:"attributes,omitempty"`
+		ID          string            `json:"id"`
+		PublishTime time.Time         `json:"publishTime"`
+	} `json:"message"`
+	Subscription string `json:"subscription"`
+}
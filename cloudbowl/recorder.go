@@ -0,0 +1,64 @@
+package cloudbowl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// one line of a recording, an arena update a bot received and the move it responded with
+type Record struct {
+	Timestamp time.Time   `json:"timestamp"`
+	Update    ArenaUpdate `json:"update"`
+	Move      Move        `json:"move"`
+}
+
+// appends every arena update and the move chosen for it to a JSONL file, so matches can be replayed later
+type Recorder struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// opens the recording at the provided path, creating it if needed and adding to the end of it if it already exists
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+func (recorder *Recorder) Record(update ArenaUpdate, move Move) error {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return recorder.encoder.Encode(Record{Timestamp: time.Now().UTC(), Update: update, Move: move})
+}
+
+func (recorder *Recorder) Close() error {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return recorder.file.Close()
+}
+
+// reads every record from a recording made by a Recorder
+func ReadRecording(r io.Reader) (result []Record, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // arena updates for big matches can be long lines
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err)
+		}
+		record.Update.AssignPlayerIds()
+		result = append(result, record)
+	}
+	return result, scanner.Err()
+}
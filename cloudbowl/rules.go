@@ -0,0 +1,82 @@
+package cloudbowl
+
+// the furthest a throw travels, in squares. This is a rule of the game, so it is the same for every player.
+const MAX_THROW_DISTANCE = 3
+
+// anything that knows where the players are standing, so the rules can be applied to an arena update as well as to
+// positions that only exist in a simulation or a plan
+type Squares interface {
+	IsOnBoard(x int, y int) bool
+	// returns the Id of the player standing on the square, if there is one
+	PlayerAt(x int, y int) (id string, found bool)
+}
+
+// determines if the square is inside the arena
+func (update ArenaUpdate) IsOnBoard(x int, y int) bool {
+	dimensions := update.Arena.Dimensions
+	return len(dimensions) == 2 && x >= 0 && x < dimensions[0] && y >= 0 && y < dimensions[1]
+}
+
+// finds who is standing on the square, if anyone is
+func (update ArenaUpdate) PlayerAt(x int, y int) (id string, found bool) {
+	for href, player := range update.Arena.State {
+		if player.X == x && player.Y == y {
+			return href, true
+		}
+	}
+	return "", false
+}
+
+// determines if walking forward would take the player into a wall or another player
+func IsForwardBlocked(squares Squares, player PlayerState) bool {
+	dx, dy := player.Direction.Step()
+	x := player.X + dx
+	y := player.Y + dy
+	if (dx == 0 && dy == 0) || !squares.IsOnBoard(x, y) {
+		return true
+	}
+	_, occupied := squares.PlayerAt(x, y)
+	return occupied
+}
+
+// finds who a throw from x,y in the direction would hit: the first player within maxDistance squares, as long as
+// they are not behind a wall. Nobody is hit when the direction isn't a valid one.
+func FirstPlayerInLine(squares Squares, x int, y int, direction Direction, maxDistance int) (id string, found bool) {
+	dx, dy := direction.Step()
+	if dx == 0 && dy == 0 {
+		return "", false
+	}
+	for i := 1; i <= maxDistance; i++ {
+		if !squares.IsOnBoard(x+dx*i, y+dy*i) {
+			return "", false
+		}
+		if id, found := squares.PlayerAt(x+dx*i, y+dy*i); found {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// determines if a throw by the player would hit anyone
+func IsAnyoneInFrontOf(squares Squares, player PlayerState) bool {
+	_, found := FirstPlayerInLine(squares, player.X, player.Y, player.Direction, MAX_THROW_DISTANCE)
+	return found
+}
+
+// lists the moves that would change something for the player, in the order forward, left, right, throw. Walking into
+// a wall or another player, and throwing with nobody in range, are left out.
+func LegalMoves(squares Squares, player PlayerState) []Move {
+	result := LegalMovements(squares, player)
+	if IsAnyoneInFrontOf(squares, player) {
+		result = append(result, Throw)
+	}
+	return result
+}
+
+// the same as LegalMoves, but leaving out throwing
+func LegalMovements(squares Squares, player PlayerState) (result []Move) {
+	if !IsForwardBlocked(squares, player) {
+		result = append(result, Forward)
+	}
+	return append(result, TurnLeft, TurnRight)
+}
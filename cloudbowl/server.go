@@ -0,0 +1,105 @@
+package cloudbowl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// the port to listen on, Cloud Run provides it in the PORT environment variable
+func Port() string {
+	if v := os.Getenv("PORT"); v != "" {
+		return v
+	}
+	return "8080"
+}
+
+// serves the provided handler on Port(), only returning if the server fails
+func ListenAndServe(handler http.Handler) error {
+	port := Port()
+	log.Printf("starting server on port :%v", port)
+	return http.ListenAndServe(":"+port, handler)
+}
+
+// responds to each arena update the game POSTs to a bot with the move chosen by play.
+// The game checks a bot is up with a GET request, which just gets a friendly message.
+// If the RECORD_FILE environment variable is set, every update and move is also appended to that file.
+func BotHandler(play func(input ArenaUpdate) Move) http.HandlerFunc {
+	return BotHandlerContext(func(ctx context.Context, input ArenaUpdate) Move { return play(input) })
+}
+
+// the same as BotHandler, but play also gets the request's context, which is cancelled if the game stops waiting for
+// the move, so a bot that searches for its move knows when to stop
+func BotHandlerContext(play func(ctx context.Context, input ArenaUpdate) Move) http.HandlerFunc {
+	var recorder *Recorder
+	if path := os.Getenv("RECORD_FILE"); path != "" {
+		var err error
+		if recorder, err = NewRecorder(path); err != nil {
+			log.Fatalf("failed to open recording: %v", err)
+		}
+		log.Printf("recording arena updates to %v", path)
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			fmt.Fprint(w, "Let the battle begin!")
+			return
+		}
+		defer req.Body.Close()
+		input, err := DecodeArenaUpdate(req.Body)
+		if err != nil {
+			log.Printf("WARN: failed to decode ArenaUpdate in response body: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		move := play(req.Context(), input)
+		fmt.Fprint(w, move)
+		if recorder != nil {
+			if err := recorder.Record(input, move); err != nil {
+				log.Printf("WARN: failed to record ArenaUpdate: %v", err)
+			}
+		}
+	}
+}
+
+// decodes an arena update sent by the game, rejecting unknown fields so that changes to the protocol are noticed
+func DecodeArenaUpdate(r io.Reader) (result ArenaUpdate, err error) {
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	err = d.Decode(&result)
+	result.AssignPlayerIds()
+	return result, err
+}
+
+// decodes an arena update that was published to Pub/Sub and pushed to one of the event driven services
+func DecodeArenaUpdateEvent(r io.Reader) (result ArenaUpdate, err error) {
+	result, _, err = DecodeReceivedArenaUpdateEvent(r)
+	return result, err
+}
+
+// the same as DecodeArenaUpdateEvent, but also returns when the player bot received the update, see RECEIVED_ATTRIBUTE.
+// Updates published without the attribute fall back to when Pub/Sub received them.
+func DecodeReceivedArenaUpdateEvent(r io.Reader) (result ArenaUpdate, received time.Time, err error) {
+	var pubsubMessageEvent PubSubMessageEvent
+	if err := json.NewDecoder(r).Decode(&pubsubMessageEvent); err != nil {
+		return result, received, fmt.Errorf("failed to decode PubSubMessageEvent: %v", err)
+	}
+	if err := json.Unmarshal(pubsubMessageEvent.Message.Data, &result); err != nil {
+		return result, received, fmt.Errorf("failed to decode ArenaUpdate from PubSubMessageEvent: %v", err)
+	}
+	result.AssignPlayerIds()
+	received = pubsubMessageEvent.Message.PublishTime
+	if value, exists := pubsubMessageEvent.Message.Attributes[RECEIVED_ATTRIBUTE]; exists {
+		milliseconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return result, received, fmt.Errorf("failed to decode the %v attribute of PubSubMessageEvent: %v", RECEIVED_ATTRIBUTE, err)
+		}
+		received = time.UnixMilli(milliseconds)
+	}
+	return result, received, nil
+}